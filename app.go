@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,6 +12,8 @@ import (
 	"strings"
 	"time"
 	"unicode"
+
+	wruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type AdType string
@@ -37,6 +40,22 @@ type Transition struct {
 	Exit  string `json:"exit"`
 }
 
+// validTransitions is the allowlist of transition effects the frontend can
+// render. Anything else coming from a remote playlist falls back to fade.
+var validTransitions = map[string]bool{
+	"fade": true, "slide": true, "zoom": true, "none": true,
+}
+
+// normalizeTransition returns the given transition name when allowlisted,
+// falling back to "fade" for anything absent or unknown.
+func normalizeTransition(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if validTransitions[name] {
+		return name
+	}
+	return "fade"
+}
+
 type AdLayout struct {
 	Fit        MediaFit `json:"fit,omitempty"`
 	PaddingPx  int      `json:"paddingPx,omitempty"`
@@ -135,9 +154,70 @@ func NewApp() *App {
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	// Prevent Windows from going to sleep / locking the screen.
+	// Keep Windows from sleeping / locking the screen, either around the
+	// clock or only during the configured AWAKE_HOURS window.
 	// Update checking is handled externally by launcher.exe.
-	PreventWindowsSleep()
+	startSleepScheduler()
+	// Power the panel itself down outside DISPLAY_POWER_HOURS, if set.
+	startDisplayPowerScheduler()
+	// Report liveness to the launcher so it can tell a hung web view from a
+	// healthy kiosk.
+	go a.heartbeatLoop()
+}
+
+// heartbeatLoop POSTs a small liveness report to the launcher every few
+// seconds. Runs only when a playlist URL is configured (launcher-managed
+// mode); standalone kiosks have nothing to report to.
+func (a *App) heartbeatLoop() {
+	if a.playlistURL == "" {
+		return
+	}
+	// playlistURL points at <base>/api/playlist on the launcher.
+	url := strings.TrimSuffix(a.playlistURL, "/playlist") + "/kiosk/heartbeat"
+	// Resolution is queried until the web view reports it (it can be zero
+	// early in startup), then reused — screens don't change size mid-run.
+	var screenW, screenH int
+	for {
+		if screenW == 0 || screenH == 0 {
+			screenW, screenH = a.screenResolution()
+		}
+		report := map[string]any{"buildNumber": BuildNumber}
+		if screenW > 0 && screenH > 0 {
+			report["screenWidth"] = screenW
+			report["screenHeight"] = screenH
+		}
+		payload, _ := json.Marshal(report)
+		if resp, err := a.client.Post(url, "application/json", bytes.NewReader(payload)); err == nil {
+			resp.Body.Close()
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// screenResolution returns the physical resolution of the screen the kiosk
+// window is on, or (0, 0) when the runtime can't report it yet.
+func (a *App) screenResolution() (int, int) {
+	if a.ctx == nil {
+		return 0, 0
+	}
+	screens, err := wruntime.ScreenGetAll(a.ctx)
+	if err != nil || len(screens) == 0 {
+		return 0, 0
+	}
+	chosen := screens[0]
+	for _, s := range screens {
+		if s.IsCurrent {
+			chosen = s
+			break
+		}
+		if s.IsPrimary {
+			chosen = s
+		}
+	}
+	if chosen.PhysicalSize.Width > 0 && chosen.PhysicalSize.Height > 0 {
+		return chosen.PhysicalSize.Width, chosen.PhysicalSize.Height
+	}
+	return chosen.Size.Width, chosen.Size.Height
 }
 
 // newCacheHandler returns an http.Handler that serves locally-cached media assets
@@ -181,21 +261,113 @@ func (a *App) GetBuildNumber() string {
 	return BuildNumber
 }
 
+// GetSleepReasserts returns how many times the sleep-prevention watchdog has
+// had to re-assert the execution state. A climbing count means some other
+// process keeps resetting it.
+func (a *App) GetSleepReasserts() int64 {
+	return SleepReassertCount()
+}
+
 // GetPlaylistURL returns the active playlist URL so the dev overlay can
 // display it. Returns an empty string when running in standalone mode.
 func (a *App) GetPlaylistURL() string {
 	return a.playlistURL
 }
 
-// FetchPlaylist fetches the remote playlist from the configured URL.
+// Playlist fetch resilience: a momentarily-unreachable launcher (restart,
+// self-update) should not blank the screen, so failed fetches retry with
+// backoff and then fall back to the last playlist that worked, persisted in
+// the cache dir.
+const (
+	playlistFetchAttempts = 3
+	playlistFetchBackoff  = time.Second // doubles per retry
+	cachedPlaylistFile    = "playlist.json"
+	// An operator-provided cold-start playlist: used only when no fetch has
+	// ever succeeded (nothing cached yet), so a brand-new kiosk shows
+	// something deterministic instead of a blank screen. The first real
+	// playlist writes the cache and supersedes it.
+	bundledPlaylistFile = "default-playlist.json"
+)
+
+// FetchPlaylist fetches the remote playlist from the configured URL, retrying
+// transient failures and falling back to the cached last-known-good playlist
+// when the launcher stays unreachable.
 // Returns an empty slice when no URL is configured (standalone mode).
-// Returns an error when a URL is configured but the request fails, so the
-// frontend can distinguish "no URL set" from "fetch actually broke".
+// Returns an error only when a URL is configured, every attempt failed and
+// there is no cached playlist to serve, so the frontend can distinguish
+// "no URL set" from "fetch actually broke".
 func (a *App) FetchPlaylist() ([]Ad, error) {
 	if a.playlistURL == "" {
 		return []Ad{}, nil
 	}
 
+	var lastErr error
+	backoff := playlistFetchBackoff
+	for attempt := 1; attempt <= playlistFetchAttempts; attempt++ {
+		ads, err := a.fetchPlaylistOnce()
+		if err == nil {
+			a.saveCachedPlaylist(ads)
+			return ads, nil
+		}
+		lastErr = err
+		if attempt < playlistFetchAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	if cached, ok := a.loadCachedPlaylist(); ok {
+		return cached, nil
+	}
+	if bundled, ok := a.loadBundledPlaylist(); ok {
+		return bundled, nil
+	}
+	return nil, lastErr
+}
+
+// DisplayConfig is the launcher-provided display configuration the frontend
+// applies at startup.
+type DisplayConfig struct {
+	// Rotation is 0, 90, 180 or 270 clockwise degrees, for screens mounted
+	// in portrait. The frontend applies it as a CSS transform.
+	Rotation int `json:"rotation"`
+}
+
+// FetchDisplayConfig reads the launcher's display configuration. Standalone
+// kiosks, unreachable launchers and malformed responses all yield the zero
+// config (no rotation) so the screen always renders something.
+func (a *App) FetchDisplayConfig() DisplayConfig {
+	var cfg DisplayConfig
+	if a.playlistURL == "" {
+		return cfg
+	}
+	// playlistURL points at <base>/api/playlist on the launcher.
+	url := strings.TrimSuffix(a.playlistURL, "/playlist") + "/display-config"
+	req, err := http.NewRequestWithContext(a.context(), http.MethodGet, url, nil)
+	if err != nil {
+		return cfg
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return cfg
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return cfg
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&cfg); err != nil {
+		return DisplayConfig{}
+	}
+	switch cfg.Rotation {
+	case 0, 90, 180, 270:
+	default:
+		cfg.Rotation = 0
+	}
+	return cfg
+}
+
+// fetchPlaylistOnce performs a single fetch + sanitize round trip.
+func (a *App) fetchPlaylistOnce() ([]Ad, error) {
 	req, err := http.NewRequestWithContext(a.context(), http.MethodGet, a.playlistURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("build request: %w", err)
@@ -220,6 +392,60 @@ func (a *App) FetchPlaylist() ([]Ad, error) {
 	return ads, nil
 }
 
+// saveCachedPlaylist persists the sanitized playlist to the cache dir
+// (atomically, temp + rename) so it survives kiosk and launcher restarts.
+func (a *App) saveCachedPlaylist(ads []Ad) {
+	if a.cacheDir == "" {
+		return
+	}
+	data, err := json.Marshal(ads)
+	if err != nil {
+		return
+	}
+	dst := filepath.Join(a.cacheDir, cachedPlaylistFile)
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, dst)
+}
+
+// loadCachedPlaylist reads the last-known-good playlist back from disk. The
+// contents are re-sanitized — the cache dir is just a directory anyone could
+// edit, so it gets the same trust as a remote payload.
+func (a *App) loadCachedPlaylist() ([]Ad, bool) {
+	if a.cacheDir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(a.cacheDir, cachedPlaylistFile))
+	if err != nil {
+		return nil, false
+	}
+	var ads []Ad
+	if err := json.Unmarshal(data, &ads); err != nil {
+		return nil, false
+	}
+	return sanitizeRemotePlaylist(ads), true
+}
+
+// loadBundledPlaylist reads the optional operator-dropped default playlist.
+// It goes through the same sanitizer as a remote payload — being on disk
+// next to the exe earns it no extra trust.
+func (a *App) loadBundledPlaylist() ([]Ad, bool) {
+	if a.cacheDir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(a.cacheDir, bundledPlaylistFile))
+	if err != nil {
+		return nil, false
+	}
+	var ads []Ad
+	if err := json.Unmarshal(data, &ads); err != nil {
+		return nil, false
+	}
+	return sanitizeRemotePlaylist(ads), true
+}
+
 // sanitizeRemotePlaylist enforces defense-in-depth on any remote playlist by
 // validating types, ensuring required fields are present, sanitizing IDs, and
 // clamping durations to safe bounds. Returns a new slice with only valid ads.
@@ -255,6 +481,11 @@ func sanitizeRemotePlaylist(ads []Ad) []Ad {
 		}
 		it.DurationMs = dur
 
+		// Reject unknown transition names from external payloads — the
+		// frontend only knows the allowlisted effects.
+		it.Transition.Enter = normalizeTransition(it.Transition.Enter)
+		it.Transition.Exit = normalizeTransition(it.Transition.Exit)
+
 		// Sanitize ID and fallback name
 		it.ID = sanitizeID(it.ID)
 		if strings.TrimSpace(it.Name) == "" {
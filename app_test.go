@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestSanitizeRemotePlaylist_DurationClamping(t *testing.T) {
 	cases := []struct {
@@ -42,3 +46,50 @@ func TestSanitizeRemotePlaylist_Filtering(t *testing.T) {
 		t.Fatalf("expected 2 valid ads, got %d", len(out))
 	}
 }
+
+func TestCachedPlaylistRoundTrip(t *testing.T) {
+	a := &App{cacheDir: t.TempDir()}
+
+	if _, ok := a.loadCachedPlaylist(); ok {
+		t.Fatal("expected no cached playlist before the first save")
+	}
+
+	a.saveCachedPlaylist([]Ad{
+		{ID: "a", Name: "A", Type: AdTypeImage, Src: "/cache/a.png", DurationMs: 5000},
+	})
+	got, ok := a.loadCachedPlaylist()
+	if !ok || len(got) != 1 || got[0].ID != "a" {
+		t.Fatalf("round trip failed: ok=%v ads=%v", ok, got)
+	}
+
+	// The cache is re-sanitized on load — a hand-edited entry with a bogus
+	// type must not reach the frontend.
+	a.saveCachedPlaylist([]Ad{{ID: "x", Type: AdType("script"), Src: "evil"}})
+	if got, _ := a.loadCachedPlaylist(); len(got) != 0 {
+		t.Fatalf("expected sanitizer to drop invalid cached ad, got %v", got)
+	}
+}
+
+func TestBundledPlaylistFallback(t *testing.T) {
+	a := &App{cacheDir: t.TempDir()}
+
+	if _, ok := a.loadBundledPlaylist(); ok {
+		t.Fatal("no bundled file — expected no fallback")
+	}
+
+	bundled := `[{"id":"welcome","name":"Welcome","type":"image","src":"/cache/welcome.png","durationMs":8000}]`
+	if err := os.WriteFile(filepath.Join(a.cacheDir, bundledPlaylistFile), []byte(bundled), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := a.loadBundledPlaylist()
+	if !ok || len(got) != 1 || got[0].ID != "welcome" {
+		t.Fatalf("bundled fallback: ok=%v ads=%v", ok, got)
+	}
+
+	// Once a real playlist has been cached it wins over the bundle: the
+	// fetch fallback consults the cache first.
+	a.saveCachedPlaylist([]Ad{{ID: "real", Name: "Real", Type: AdTypeImage, Src: "/cache/real.png", DurationMs: 5000}})
+	if cached, ok := a.loadCachedPlaylist(); !ok || cached[0].ID != "real" {
+		t.Fatalf("cached playlist should supersede the bundle: %v", cached)
+	}
+}
@@ -336,6 +336,71 @@ function App() {
     return () => window.removeEventListener("keydown", onKey);
   }, [devMode, navigate, refreshPlaylist]);
 
+  // ── Remote screenshot capture ─────────────────────────────────────────────
+  // The admin can demand a fresh capture through the same command channel as
+  // next/prev. The web view cannot read its own pixels wholesale, so the
+  // capture draws the active media element (image or paused video frame)
+  // onto a canvas; HTML and embed creatives, which live in an iframe we
+  // cannot rasterize, get a labelled placeholder card instead.
+  const captureScreenshot = useCallback(() => {
+    const media = document.querySelector<HTMLImageElement | HTMLVideoElement>(
+      ".ad-card img, .ad-card video",
+    );
+    const canvas = document.createElement("canvas");
+    canvas.width =
+      (media instanceof HTMLVideoElement ? media.videoWidth : media?.naturalWidth) ||
+      window.innerWidth;
+    canvas.height =
+      (media instanceof HTMLVideoElement ? media.videoHeight : media?.naturalHeight) ||
+      window.innerHeight;
+    const ctx = canvas.getContext("2d");
+    if (!ctx) return;
+
+    const placeholderCanvas = () => {
+      const clean = document.createElement("canvas");
+      clean.width = canvas.width;
+      clean.height = canvas.height;
+      const cctx = clean.getContext("2d");
+      if (!cctx) return null;
+      cctx.fillStyle = "#1a1a1a";
+      cctx.fillRect(0, 0, clean.width, clean.height);
+      cctx.fillStyle = "#e0e0e0";
+      cctx.font = `${Math.max(24, Math.round(clean.width / 40))}px sans-serif`;
+      cctx.textAlign = "center";
+      cctx.fillText(
+        "active creative not capturable",
+        clean.width / 2,
+        clean.height / 2,
+      );
+      return clean;
+    };
+
+    let out: HTMLCanvasElement | null = canvas;
+    try {
+      if (!media) throw new Error("no media element");
+      ctx.drawImage(media, 0, 0, canvas.width, canvas.height);
+      // A canvas tainted by cross-origin media throws here, not at
+      // drawImage — and stays tainted, so the fallback needs a fresh one.
+      canvas.toDataURL("image/jpeg");
+    } catch {
+      out = placeholderCanvas();
+    }
+    if (!out) return;
+
+    out.toBlob(
+      (blob) => {
+        if (!blob) return;
+        void fetch("http://localhost:6969/api/kiosk/screenshot", {
+          method: "POST",
+          headers: { "Content-Type": "image/jpeg" },
+          body: blob,
+        }).catch(() => {});
+      },
+      "image/jpeg",
+      0.8,
+    );
+  }, []);
+
   // ── Remote nav poll (admin dashboard ← → buttons) ─────────────────────────
   // Long-polls the launcher every ~2 s. When the admin presses next/prev the
   // launcher queues a command and the next poll picks it up immediately.
@@ -351,6 +416,7 @@ function App() {
             if (cmd === "next") navigate(1);
             else if (cmd === "prev") navigate(-1);
             else if (cmd === "refresh") void refreshPlaylist();
+            else if (cmd === "screenshot") captureScreenshot();
           }
         } catch {
           // Launcher unreachable — wait a bit before retrying
@@ -363,7 +429,7 @@ function App() {
     return () => {
       cancelled = true;
     };
-  }, [navigate, refreshPlaylist]);
+  }, [navigate, refreshPlaylist, captureScreenshot]);
 
 
 
@@ -49,7 +49,12 @@ export default function ImageAd({ src, alt, layout }: Props) {
 
   return (
     <div style={wrapperStyle}>
-      <img style={mediaStyle} src={src} alt={alt ?? "Sponsored image"} />
+      <img
+        style={mediaStyle}
+        src={src}
+        alt={alt ?? "Sponsored image"}
+        crossOrigin="anonymous"
+      />
     </div>
   );
 }
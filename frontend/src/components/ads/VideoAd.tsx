@@ -72,6 +72,7 @@ export default function VideoAd({ src, poster, layout }: Props) {
         muted
         loop
         playsInline
+        crossOrigin="anonymous"
         // @ts-ignore – non-standard but needed for some browsers
         controls={false}
       />
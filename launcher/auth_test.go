@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthRateLimit(t *testing.T) {
+	authAttemptsMu.Lock()
+	authAttempts = map[string][]time.Time{}
+	authAttemptsMu.Unlock()
+
+	const ip = "203.0.113.7"
+	for i := 0; i < authMaxAttempts; i++ {
+		if authRateLimited(ip) {
+			t.Fatalf("attempt %d should be allowed", i+1)
+		}
+	}
+	if !authRateLimited(ip) {
+		t.Fatal("attempt past the window cap should be throttled")
+	}
+	// A different IP is unaffected.
+	if authRateLimited("198.51.100.2") {
+		t.Fatal("other IPs should have their own window")
+	}
+
+	// Attempts age out: backdate the stored entries past the window.
+	authAttemptsMu.Lock()
+	old := time.Now().Add(-authWindow - time.Second)
+	for i := range authAttempts[ip] {
+		authAttempts[ip][i] = old
+	}
+	authAttemptsMu.Unlock()
+	if authRateLimited(ip) {
+		t.Fatal("expired attempts should no longer count")
+	}
+}
+
+func TestClientIPTrustedProxy(t *testing.T) {
+	orig := trustedProxyNets
+	defer func() { trustedProxyNets = orig }()
+	trustedProxyNets = parseTrustedProxies("127.0.0.1, 10.0.0.0/8, bogus")
+
+	mk := func(remote, xff, realIP string) *http.Request {
+		r := httptest.NewRequest("POST", "/api/admin/auth", nil)
+		r.RemoteAddr = remote
+		if xff != "" {
+			r.Header.Set("X-Forwarded-For", xff)
+		}
+		if realIP != "" {
+			r.Header.Set("X-Real-IP", realIP)
+		}
+		return r
+	}
+
+	cases := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{"direct connection", mk("192.0.2.9:1234", "", ""), "192.0.2.9"},
+		{"spoofed header from untrusted peer", mk("192.0.2.9:1234", "1.2.3.4", ""), "192.0.2.9"},
+		{"forwarded via loopback proxy", mk("127.0.0.1:5555", "203.0.113.7", ""), "203.0.113.7"},
+		{"multi-hop takes rightmost", mk("10.1.2.3:80", "1.2.3.4, 203.0.113.7", ""), "203.0.113.7"},
+		{"x-real-ip fallback", mk("127.0.0.1:5555", "", "203.0.113.8"), "203.0.113.8"},
+		{"trusted proxy without headers", mk("127.0.0.1:5555", "", ""), "127.0.0.1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := clientIP(c.req); got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	idemMu.Lock()
+	idemCache = map[string]idemEntry{}
+	idemMu.Unlock()
+
+	calls := 0
+	handler := withIdempotency(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	})
+
+	do := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/submit-ads", nil)
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	first := do("k1")
+	replay := do("k1")
+	if calls != 1 {
+		t.Fatalf("handler ran %d times for one key, want 1", calls)
+	}
+	if replay.Body.String() != first.Body.String() {
+		t.Fatalf("replay body %q differs from original %q", replay.Body.String(), first.Body.String())
+	}
+	if replay.Header().Get("Idempotency-Replayed") != "true" {
+		t.Fatal("replay should be marked")
+	}
+
+	do("k2")
+	do("") // no key — always runs
+	do("")
+	if calls != 4 {
+		t.Fatalf("calls = %d, want 4 (new key + two keyless)", calls)
+	}
+}
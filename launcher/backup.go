@@ -0,0 +1,175 @@
+package main
+
+// backup.go — automatic daily backups of the ad store.
+//
+// The ads.json store is the single source of truth for the pipeline, so once
+// a day (configurable via BACKUP_INTERVAL) a consistent snapshot of it is
+// written into a timestamped zip under backups/ next to the launcher binary.
+// The last N snapshots (BACKUP_RETAIN, default 7) are kept; older ones are
+// pruned. Admins can trigger a snapshot on demand and list existing ones.
+
+import (
+	"archive/zip"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"encoding/json"
+)
+
+// backupsDir is initialised in main() alongside mediaDir.
+var backupsDir string
+
+const (
+	defaultBackupInterval = 24 * time.Hour
+	defaultBackupRetain   = 7
+	backupPrefix          = "ads-"
+)
+
+func backupInterval() time.Duration {
+	if v := os.Getenv("BACKUP_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("Backup: invalid BACKUP_INTERVAL %q — using %s", v, defaultBackupInterval)
+	}
+	return defaultBackupInterval
+}
+
+func backupRetain() int {
+	if v := os.Getenv("BACKUP_RETAIN"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("Backup: invalid BACKUP_RETAIN %q — using %d", v, defaultBackupRetain)
+	}
+	return defaultBackupRetain
+}
+
+// backupLoop snapshots the store once per interval. Run as a goroutine.
+func backupLoop() {
+	interval := backupInterval()
+	for {
+		time.Sleep(interval)
+		if name, err := writeBackup(); err != nil {
+			log.Printf("Backup: %v", err)
+		} else {
+			log.Printf("Backup: wrote %s", name)
+		}
+	}
+}
+
+// writeBackup snapshots the in-memory store (a consistent view taken under
+// the store lock) into a timestamped zip and prunes old snapshots. Returns
+// the created filename.
+func writeBackup() (string, error) {
+	if backupsDir == "" {
+		return "", fmt.Errorf("backups directory not initialised")
+	}
+	data, err := dbMarshalStore()
+	if err != nil {
+		return "", fmt.Errorf("marshal store: %w", err)
+	}
+
+	name := backupPrefix + time.Now().UTC().Format("20060102-150405") + ".zip"
+	path := filepath.Join(backupsDir, name)
+
+	// Write to a temp file then rename, same as every other write here.
+	tmp, err := os.CreateTemp(backupsDir, "backup-*")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmp.Name()
+
+	zw := zip.NewWriter(tmp)
+	f, err := zw.Create("ads.json")
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmpName)
+		return "", fmt.Errorf("write zip: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+
+	pruneBackups()
+	return name, nil
+}
+
+// pruneBackups deletes the oldest snapshots beyond the retention count.
+// Timestamped names sort chronologically, so lexicographic order works.
+func pruneBackups() {
+	entries, err := os.ReadDir(backupsDir)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), backupPrefix) && strings.HasSuffix(e.Name(), ".zip") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	retain := backupRetain()
+	for len(names) > retain {
+		if err := os.Remove(filepath.Join(backupsDir, names[0])); err == nil {
+			log.Printf("Backup: pruned %s", names[0])
+		}
+		names = names[1:]
+	}
+}
+
+// handleAdminBackupNow triggers an on-demand snapshot.
+func handleAdminBackupNow(w http.ResponseWriter, r *http.Request) {
+	name, err := writeBackup()
+	if err != nil {
+		log.Printf("Backup: on-demand failed: %v", err)
+		http.Error(w, `{"error":"backup failed"}`, http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Backup: on-demand wrote %s", name)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "file": name})
+}
+
+// handleAdminListBackups lists available snapshots, newest first.
+func handleAdminListBackups(w http.ResponseWriter, r *http.Request) {
+	type backupItem struct {
+		Name      string `json:"name"`
+		SizeBytes int64  `json:"sizeBytes"`
+		CreatedAt string `json:"createdAt"`
+	}
+	out := []backupItem{}
+	if entries, err := os.ReadDir(backupsDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasPrefix(e.Name(), backupPrefix) || !strings.HasSuffix(e.Name(), ".zip") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			out = append(out, backupItem{
+				Name:      e.Name(),
+				SizeBytes: info.Size(),
+				CreatedAt: info.ModTime().UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name > out[j].Name })
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
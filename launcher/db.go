@@ -14,8 +14,10 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,14 +30,100 @@ type adRecord struct {
 	OriginalURL string `json:"original_url"`
 	DurationMs  int    `json:"duration_ms"`
 	SubmittedBy string `json:"submitted_by"`
+	EnterFx     string `json:"enter_fx,omitempty"` // transition effects; empty means fade
+	ExitFx      string `json:"exit_fx,omitempty"`
 	Status      string `json:"status"`
 	SortOrder   int    `json:"sort_order"`
 	SubmittedAt string `json:"submitted_at"` // RFC3339
+	// Owner fields split out of SubmittedBy ("Name <email>") at save time, plus
+	// lifecycle context the admin view needs to survive a restart.
+	OwnerName    string `json:"owner_name,omitempty"`
+	OwnerEmail   string `json:"owner_email,omitempty"`
+	ApprovedAt   string `json:"approved_at,omitempty"` // RFC3339; set on first approval
+	ShownOnKiosk bool   `json:"shown_on_kiosk,omitempty"`
+	// IsDefault marks an ad as part of the boot/fallback set served whenever
+	// the live playlist is empty, so the screen is never blank.
+	IsDefault bool `json:"is_default,omitempty"`
+	// Playback-failure bookkeeping, reported by the kiosk via
+	// /api/kiosk/report-error (dead URL, decode failure, …).
+	ErrorCount  int    `json:"error_count,omitempty"`
+	LastError   string `json:"last_error,omitempty"`
+	LastErrorAt string `json:"last_error_at,omitempty"` // RFC3339
+	// Broken marks an ad that was auto-demoted from live after repeated
+	// playback failures; it stays set until an admin re-activates the ad.
+	Broken bool `json:"broken,omitempty"`
+	// DurationMode is how duration_ms was chosen: "" / "fixed" means the
+	// submitted (or default) value; "auto" means derived from the video's
+	// actual length once the media was probed.
+	DurationMode string `json:"duration_mode,omitempty"`
+	// DenyReason is the admin's note to the submitter explaining a denial.
+	DenyReason string `json:"deny_reason,omitempty"`
+	// RemovedBy records who pulled a removed ad: "admin" or "user".
+	RemovedBy string `json:"removed_by,omitempty"`
+}
+
+// splitSubmitter breaks a "Name <email>" submitter string into its parts.
+// A bare email yields an empty name.
+func splitSubmitter(s string) (name, email string) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "<"); i >= 0 && strings.HasSuffix(s, ">") {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1 : len(s)-1])
+	}
+	return "", s
+}
+
+// playlistRecord is a named, ordered set of ad IDs (e.g. "lobby") so one
+// launcher can drive several screens, each pointed at a different name.
+type playlistRecord struct {
+	Name  string   `json:"name"`
+	AdIDs []string `json:"ad_ids"`
+}
+
+// scheduleRecord maps a daily time range to a named playlist (day-parting:
+// morning menu vs evening menu). Times are "HH:MM" on the server clock;
+// start is inclusive, end exclusive, and a range with start after end wraps
+// past midnight.
+type scheduleRecord struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Playlist string `json:"playlist"`
 }
 
 // adStore is the root JSON object.
 type adStore struct {
-	Ads []adRecord `json:"ads"`
+	Ads       []adRecord        `json:"ads"`
+	Playlists []playlistRecord  `json:"playlists,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+	// Emergency, when set, preempts every playlist until cleared. It lives
+	// outside Ads so the live set is untouched and resumes unchanged.
+	Emergency *adRecord `json:"emergency,omitempty"`
+	// Banned submitters, keyed by email (the same ownership key the public
+	// endpoints use). Persisted so bans survive restarts.
+	Banned []bannedRecord `json:"banned,omitempty"`
+	// Schedule day-parts the default playlist: entries are checked in order
+	// and the first range containing the current time wins.
+	Schedule []scheduleRecord `json:"schedule,omitempty"`
+	// Media tracks reference counts for local media files, keyed by base
+	// name. Content-hash storage means several ads can share one file; the
+	// count keeps deletion honest (see deleteMediaFile).
+	Media map[string]int `json:"media,omitempty"`
+	// Sessions are live admin tokens (hashed — a leaked store must not hand
+	// over sessions), persisted so self-update re-execs don't log every
+	// admin out.
+	Sessions []sessionRecord `json:"sessions,omitempty"`
+}
+
+// sessionRecord is one persisted admin session.
+type sessionRecord struct {
+	TokenHash string `json:"token_hash"`
+	ExpiresAt string `json:"expires_at"` // RFC3339
+}
+
+// bannedRecord is one deny-list entry.
+type bannedRecord struct {
+	Email    string `json:"email"`
+	Reason   string `json:"reason,omitempty"`
+	BannedAt string `json:"banned_at"` // RFC3339
 }
 
 var (
@@ -50,11 +138,139 @@ const (
 	adStatusApproved  = "approved"
 	adStatusLive      = "live"
 	adStatusDenied    = "denied"
+	// Removed is a terminal tombstone: the record stays for submitter status
+	// polling, with removed_by saying who pulled it (admin vs the submitter
+	// retracting their own ad).
+	adStatusRemoved = "removed"
+)
+
+// Actor values for removedBy.
+const (
+	removedByAdmin = "admin"
+	removedByUser  = "user"
 )
 
+// Activation-order setting: controls the order in which dbMoveApprovedToLive
+// appends approved ads to the live rotation.
+const (
+	settingActivationOrder = "activation_order"
+
+	activationOrderOldest = "oldest" // submission order — default
+	activationOrderNewest = "newest"
+	activationOrderName   = "name" // alphabetical by ad name
+)
+
+// Default-duration setting: applied when a submission arrives without an
+// explicit duration (the dash sends 0 for "whatever the default is").
+const (
+	settingDefaultDurationMs  = "default_duration_ms"
+	fallbackDefaultDurationMs = 10000
+)
+
+// Broken-ad demotion: an ad that racks up this many playback errors within
+// playbackErrorWindow is pulled out of the live rotation automatically so it
+// can't keep black-framing the screen. Threshold tunable per install.
+const (
+	settingBrokenThreshold  = "broken_ad_threshold"
+	fallbackBrokenThreshold = 3
+	playbackErrorWindow     = 10 * time.Minute
+)
+
+// Submissions toggle: operators can freeze new submissions (full queue,
+// moderators offline) without taking the dash down. Open unless explicitly
+// set to "false".
+const settingSubmissionsOpen = "submissions_open"
+
+// dbSubmissionsOpen reports whether the public submit endpoint accepts ads.
+func dbSubmissionsOpen() bool {
+	return dbGetSetting(settingSubmissionsOpen, "true") != "false"
+}
+
+// Display rotation: how the kiosk should rotate its rendering for screens
+// mounted in portrait. One of 0, 90, 180, 270 (clockwise degrees); anything
+// else in the store is treated as 0.
+const settingDisplayRotation = "display_rotation"
+
+// dbDisplayRotation returns the configured rotation, normalized to a valid
+// right-angle value.
+func dbDisplayRotation() int {
+	switch dbGetSetting(settingDisplayRotation, "0") {
+	case "90":
+		return 90
+	case "180":
+		return 180
+	case "270":
+		return 270
+	}
+	return 0
+}
+
+// Update history: written just before a self-update re-execs, so after the
+// restart the dashboard can say "updated from build-40 to build-42 at …".
+const (
+	settingPreviousBuild = "previous_build"
+	settingLastUpdateAt  = "last_update_at"
+)
+
+// Live-set cap: guards against "reload pushes everything live" surprises.
+// max_live_ads limits how many ads may be live at once (0 / unset keeps the
+// historical unlimited behavior); live_cap_behavior chooses what happens to a
+// single activation at the cap — reject it (default) or evict the oldest.
+const (
+	settingMaxLiveAds      = "max_live_ads"
+	settingLiveCapBehavior = "live_cap_behavior"
+	liveCapEvict           = "evict"
+)
+
+// dbMaxLiveAds returns the configured live-set cap; 0 means unlimited.
+func dbMaxLiveAds() int {
+	n, err := strconv.Atoi(dbGetSetting(settingMaxLiveAds, ""))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// brokenAdThreshold returns the configured demotion threshold, or the
+// fallback when unset/garbage. 0 disables automatic demotion.
+func brokenAdThreshold() int {
+	v := dbGetSetting(settingBrokenThreshold, "")
+	if v == "" {
+		return fallbackBrokenThreshold
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return fallbackBrokenThreshold
+	}
+	return n
+}
+
+// dbDefaultDurationMs returns the operator-configured default ad duration,
+// clamped to the same [1000, 30000] range the per-ad duration endpoint
+// enforces, or the built-in fallback when the setting is unset or garbage.
+func dbDefaultDurationMs() int {
+	n, err := strconv.Atoi(dbGetSetting(settingDefaultDurationMs, ""))
+	if err != nil || n <= 0 {
+		return fallbackDefaultDurationMs
+	}
+	if n < 1000 {
+		return 1000
+	}
+	if n > 30000 {
+		return 30000
+	}
+	return n
+}
+
 // ─── Bootstrap ────────────────────────────────────────────────────────────────
 
 // initDB loads (or creates) the JSON store. Must be called before any handler.
+// dbBroken is set when initDB could not load the store (e.g. a corrupt
+// ads.json). The process stays up in a visible degraded mode instead of
+// panicking: serveDash swaps the whole API for a maintenance response, and
+// mutations refuse to save over the unreadable file.
+var dbBroken atomic.Bool
+
 func initDB() error {
 	storePath = filepath.Join(exeDirectory(), "ads.json")
 
@@ -68,18 +284,61 @@ func initDB() error {
 		return nil
 	}
 	if err != nil {
+		dbBroken.Store(true)
 		return fmt.Errorf("read store: %w", err)
 	}
 	if err := json.Unmarshal(data, &store); err != nil {
+		dbBroken.Store(true)
 		return fmt.Errorf("parse store: %w", err)
 	}
 	log.Printf("DB: loaded %d ad(s) from %s", len(store.Ads), storePath)
+	migrateStore()
 	return nil
 }
 
+// migrateStore upgrades records written by older builds in place. New fields
+// ship through the normal update mechanism, so a store created before a
+// field existed gets its default filled in here on the first boot after the
+// update. Idempotent — a store that is already current is left untouched.
+// Caller must hold storeMu (write lock).
+func migrateStore() {
+	changed := 0
+	if store.Settings == nil {
+		store.Settings = map[string]string{}
+	}
+	for i := range store.Ads {
+		r := &store.Ads[i]
+		if r.OwnerName == "" && r.OwnerEmail == "" && r.SubmittedBy != "" {
+			r.OwnerName, r.OwnerEmail = splitSubmitter(r.SubmittedBy)
+			log.Printf("DB: migrate — ad %q: owner fields backfilled from submitted_by", r.AdID)
+			changed++
+		}
+		if r.SubmittedAt == "" {
+			r.SubmittedAt = time.Now().UTC().Format(time.RFC3339)
+			log.Printf("DB: migrate — ad %q: submitted_at defaulted", r.AdID)
+			changed++
+		}
+		if r.Status == adStatusLive && !r.ShownOnKiosk {
+			r.ShownOnKiosk = true
+			log.Printf("DB: migrate — ad %q: shown_on_kiosk set for live ad", r.AdID)
+			changed++
+		}
+	}
+	if changed > 0 {
+		saveStore()
+		log.Printf("DB: migration applied %d change(s)", changed)
+	}
+}
+
 // saveStore persists the in-memory store to disk atomically.
 // Caller must hold storeMu (write lock).
 func saveStore() {
+	if dbBroken.Load() {
+		// Never overwrite a store we could not read — the operator may still
+		// be able to repair it by hand.
+		log.Printf("DB: store is broken — refusing to save")
+		return
+	}
 	data, err := json.MarshalIndent(store, "", "  ")
 	if err != nil {
 		log.Printf("DB: marshal error: %v", err)
@@ -99,14 +358,26 @@ func saveStore() {
 
 func recToAd(r adRecord) kioskAd {
 	return kioskAd{
-		ID:          r.AdID,
-		Name:        r.Name,
-		Type:        r.AdType,
-		DurationMs:  r.DurationMs,
-		Src:         r.Src,
-		SubmittedBy: r.SubmittedBy,
-		SubmittedAt: r.SubmittedAt,
-		Transition:  adTransition{Enter: "fade", Exit: "fade"},
+		ID:           r.AdID,
+		Name:         r.Name,
+		Type:         r.AdType,
+		DurationMs:   r.DurationMs,
+		DurationMode: r.DurationMode,
+		Src:          r.Src,
+		SubmittedBy:  r.SubmittedBy,
+		SubmittedAt:  r.SubmittedAt,
+		Transition:   adTransition{Enter: normalizeTransition(r.EnterFx), Exit: normalizeTransition(r.ExitFx)},
+		MediaLocal:   strings.HasPrefix(r.Src, "/media/"),
+
+		OwnerName:    r.OwnerName,
+		ApprovedAt:   r.ApprovedAt,
+		ShownOnKiosk: r.ShownOnKiosk,
+		IsDefault:    r.IsDefault,
+
+		ErrorCount:  r.ErrorCount,
+		LastError:   r.LastError,
+		LastErrorAt: r.LastErrorAt,
+		Broken:      r.Broken,
 	}
 }
 
@@ -150,6 +421,33 @@ func dbLiveOrdered() []kioskAd {
 	return out
 }
 
+// dbGetAd returns a single ad by ID.
+func dbGetAd(adID string) (kioskAd, bool) {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	i := findIdx(adID)
+	if i < 0 {
+		return kioskAd{}, false
+	}
+	return recToAd(store.Ads[i]), true
+}
+
+// dbFindAdAnyStage resolves an ad ID against every stage the kiosk could be
+// showing — the emergency slot first (it overrides the playlist), then the
+// main records whatever their status. The returned stage is "emergency" or
+// the record's status.
+func dbFindAdAnyStage(adID string) (kioskAd, string, bool) {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	if store.Emergency != nil && store.Emergency.AdID == adID {
+		return recToAd(*store.Emergency), "emergency", true
+	}
+	if i := findIdx(adID); i >= 0 {
+		return recToAd(store.Ads[i]), store.Ads[i].Status, true
+	}
+	return kioskAd{}, "", false
+}
+
 // findIdx returns the index of the ad with the given ad_id, or -1 if not found.
 // Caller must hold storeMu.
 func findIdx(adID string) int {
@@ -174,6 +472,11 @@ func maxLiveSortOrder() int {
 }
 
 // ─── Mutations ────────────────────────────────────────────────────────────────
+//
+// Every mutation below takes the storeMu write lock for its full duration, so
+// live-set operations (clear, reorder, activate) serialize and can never
+// interleave into duplicate or gapped sort_orders. Keep it that way: any new
+// mutation must do all of its read-modify-write under a single Lock().
 
 // dbSaveAd persists a newly-submitted ad. Idempotent on duplicate ad_id.
 func dbSaveAd(ad kioskAd, originalURL string) error {
@@ -191,11 +494,17 @@ func dbSaveAd(ad kioskAd, originalURL string) error {
 			OriginalURL: originalURL,
 			DurationMs:  ad.DurationMs,
 			SubmittedBy: ad.SubmittedBy,
+			EnterFx:     ad.Transition.Enter,
+			ExitFx:      ad.Transition.Exit,
 			Status:      adStatusSubmitted,
 			SortOrder:   0,
 			SubmittedAt: time.Now().UTC().Format(time.RFC3339),
 		},
 	)
+	rec := &store.Ads[len(store.Ads)-1]
+	rec.OwnerName, rec.OwnerEmail = splitSubmitter(ad.SubmittedBy)
+	rec.DurationMode = ad.DurationMode
+	mediaRefLocked(ad.Src)
 	saveStore()
 	return nil
 }
@@ -208,6 +517,9 @@ func dbUpdateSrc(adID, src string) {
 	if i < 0 {
 		return
 	}
+	if store.Ads[i].Src != src {
+		mediaRefLocked(src)
+	}
 	store.Ads[i].Src = src
 	saveStore()
 }
@@ -226,6 +538,129 @@ func dbSetDuration(adID string, durationMs int) bool {
 	return true
 }
 
+// dbSetEmergency installs the broadcast ad that preempts all playlists.
+func dbSetEmergency(ad kioskAd) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store.Emergency = &adRecord{
+		AdID:        ad.ID,
+		Name:        ad.Name,
+		AdType:      ad.Type,
+		Src:         ad.Src,
+		DurationMs:  ad.DurationMs,
+		EnterFx:     ad.Transition.Enter,
+		ExitFx:      ad.Transition.Exit,
+		SubmittedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	saveStore()
+}
+
+// dbClearEmergency removes the broadcast ad. Returns true when one was set.
+func dbClearEmergency() bool {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if store.Emergency == nil {
+		return false
+	}
+	store.Emergency = nil
+	saveStore()
+	return true
+}
+
+// dbEmergencyAd returns the broadcast ad, if any.
+func dbEmergencyAd() (kioskAd, bool) {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	if store.Emergency == nil {
+		return kioskAd{}, false
+	}
+	return recToAd(*store.Emergency), true
+}
+
+// dbSetDefault flags or unflags an ad as part of the fallback set.
+// Returns true when the record exists.
+func dbSetDefault(adID string, isDefault bool) bool {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	i := findIdx(adID)
+	if i < 0 {
+		return false
+	}
+	store.Ads[i].IsDefault = isDefault
+	saveStore()
+	return true
+}
+
+// dbDefaultAds returns the fallback set in stored order, any status.
+func dbDefaultAds() []kioskAd {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	out := []kioskAd{}
+	for _, r := range store.Ads {
+		if r.IsDefault {
+			out = append(out, recToAd(r))
+		}
+	}
+	return out
+}
+
+// dbRecordPlaybackError notes a kiosk-reported playback failure against an
+// ad. The message is truncated so a pathological client can't bloat the
+// store. Errors older than playbackErrorWindow don't accumulate: the count
+// restarts, so only a burst of recent failures trips the demotion threshold.
+// Returns the new error count, whether the ad was demoted out of live on
+// this report, and false if the ad is unknown. Demotion happens at most once
+// per incident — the status check runs under the same lock as the increment,
+// so concurrent reports can't double-demote.
+func dbRecordPlaybackError(adID, msg string) (count int, demoted, found bool) {
+	const maxErrLen = 300
+	if len(msg) > maxErrLen {
+		msg = msg[:maxErrLen]
+	}
+	threshold := brokenAdThreshold()
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	i := findIdx(adID)
+	if i < 0 {
+		return 0, false, false
+	}
+	now := time.Now().UTC()
+	if t, err := time.Parse(time.RFC3339, store.Ads[i].LastErrorAt); err != nil || now.Sub(t) > playbackErrorWindow {
+		store.Ads[i].ErrorCount = 0
+	}
+	store.Ads[i].ErrorCount++
+	store.Ads[i].LastError = msg
+	store.Ads[i].LastErrorAt = now.Format(time.RFC3339)
+
+	if threshold > 0 && store.Ads[i].Status == adStatusLive && store.Ads[i].ErrorCount >= threshold {
+		store.Ads[i].Status = adStatusApproved
+		store.Ads[i].Broken = true
+		demoted = true
+	}
+	saveStore()
+	return store.Ads[i].ErrorCount, demoted, true
+}
+
+// dbUpdateAd applies a partial edit to an ad: only non-nil fields are
+// written. Returns the updated record and true when the ad exists.
+func dbUpdateAd(adID string, name *string, durationMs *int) (adRecord, bool) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	i := findIdx(adID)
+	if i < 0 {
+		return adRecord{}, false
+	}
+	if name != nil {
+		store.Ads[i].Name = *name
+	}
+	if durationMs != nil {
+		store.Ads[i].DurationMs = *durationMs
+	}
+	saveStore()
+	return store.Ads[i], true
+}
+
 // dbSetStatus transitions an ad to a new status.
 // Returns true when the record was found and updated.
 func dbSetStatus(adID, newStatus string) bool {
@@ -236,22 +671,120 @@ func dbSetStatus(adID, newStatus string) bool {
 		return false
 	}
 	store.Ads[i].Status = newStatus
+	if newStatus == adStatusApproved && store.Ads[i].ApprovedAt == "" {
+		store.Ads[i].ApprovedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if newStatus != adStatusDenied {
+		// A second chance wipes the old note.
+		store.Ads[i].DenyReason = ""
+	}
 	saveStore()
 	return true
 }
 
-// dbMoveToLive sets status to "live" and appends to the end of the sort order.
-func dbMoveToLive(adID string) bool {
+// dbTransition moves an ad to a new status only when it is currently in the
+// expected prior one — the guard that keeps two admins acting on the same ad
+// from both "succeeding". The status re-read and the write happen under one
+// lock hold, so concurrent transitions serialise deterministically: the
+// first wins, the second gets conflict (with the status it actually found).
+func dbTransition(adID, from, to string) (ok, conflict bool, current string) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	i := findIdx(adID)
+	if i < 0 {
+		return false, false, ""
+	}
+	if store.Ads[i].Status != from {
+		return false, true, store.Ads[i].Status
+	}
+	store.Ads[i].Status = to
+	if to == adStatusApproved && store.Ads[i].ApprovedAt == "" {
+		store.Ads[i].ApprovedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if to != adStatusDenied {
+		store.Ads[i].DenyReason = ""
+	}
+	saveStore()
+	return true, false, to
+}
+
+// dbDenyAd moves an ad to denied with an optional note for the submitter.
+func dbDenyAd(adID, reason string) bool {
 	storeMu.Lock()
 	defer storeMu.Unlock()
 	i := findIdx(adID)
 	if i < 0 {
 		return false
 	}
+	store.Ads[i].Status = adStatusDenied
+	store.Ads[i].DenyReason = strings.TrimSpace(reason)
+	saveStore()
+	return true
+}
+
+// liveCountLocked counts live ads. Caller must hold storeMu.
+func liveCountLocked() int {
+	n := 0
+	for _, r := range store.Ads {
+		if r.Status == adStatusLive {
+			n++
+		}
+	}
+	return n
+}
+
+// evictOldestLiveLocked demotes the live ad with the lowest sort order back
+// to approved and returns its ID ("" when the live set is empty). Caller must
+// hold storeMu.
+func evictOldestLiveLocked() string {
+	oldest := -1
+	for i, r := range store.Ads {
+		if r.Status != adStatusLive {
+			continue
+		}
+		if oldest < 0 || r.SortOrder < store.Ads[oldest].SortOrder {
+			oldest = i
+		}
+	}
+	if oldest < 0 {
+		return ""
+	}
+	store.Ads[oldest].Status = adStatusApproved
+	store.Ads[oldest].SortOrder = 0
+	return store.Ads[oldest].AdID
+}
+
+// dbMoveToLive sets status to "live" and appends to the end of the sort
+// order, honouring the max_live_ads cap: at the cap it either rejects
+// (capHit true, nothing changed) or — in evict mode — demotes the oldest
+// live ad first, returning its ID in evicted. Only approved ads may be
+// activated: anything else (including an ad a concurrent admin already
+// activated) reports conflict with the status actually found, so duplicate
+// activations can't double-append sort orders.
+func dbMoveToLive(adID string) (ok bool, evicted string, capHit, conflict bool) {
+	cap := dbMaxLiveAds()
+	evictMode := dbGetSetting(settingLiveCapBehavior, "") == liveCapEvict
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	i := findIdx(adID)
+	if i < 0 {
+		return false, "", false, false
+	}
+	if store.Ads[i].Status != adStatusApproved {
+		return false, "", false, true
+	}
+	if cap > 0 && liveCountLocked() >= cap {
+		if !evictMode {
+			return false, "", true, false
+		}
+		evicted = evictOldestLiveLocked()
+	}
 	store.Ads[i].Status = adStatusLive
+	store.Ads[i].ShownOnKiosk = true
 	store.Ads[i].SortOrder = maxLiveSortOrder() + 1
 	saveStore()
-	return true
+	return true, evicted, false, false
 }
 
 // dbMoveBackToApproved moves a live ad back to the approved/unused stage.
@@ -270,6 +803,8 @@ func dbMoveBackToApproved(adID string) bool {
 
 // dbDeleteByOwner removes an ad only when the submitter email matches.
 // Returns (src, found, owned). Caller should call deleteMediaFile(src).
+// The record is kept as a "removed" tombstone (actor: user) so status
+// polling can tell the submitter they retracted it themselves.
 func dbDeleteByOwner(adID, email string) (src string, found bool, owned bool) {
 	storeMu.Lock()
 	defer storeMu.Unlock()
@@ -283,11 +818,35 @@ func dbDeleteByOwner(adID, email string) (src string, found bool, owned bool) {
 		return "", true, false
 	}
 	src = r.Src
-	store.Ads = append(store.Ads[:i], store.Ads[i+1:]...)
+	markRemovedLocked(i, removedByUser)
 	saveStore()
 	return src, true, true
 }
 
+// markRemovedLocked turns the record at index i into a removed tombstone.
+// Caller must hold storeMu.
+func markRemovedLocked(i int, actor string) {
+	store.Ads[i].Status = adStatusRemoved
+	store.Ads[i].RemovedBy = actor
+	store.Ads[i].SortOrder = 0
+	store.Ads[i].Src = "" // media is reaped by the caller
+}
+
+// dbRemove tombstones an ad as removed-by-admin. Returns the src path (for
+// media cleanup) and whether the ad existed.
+func dbRemove(adID string) (src string, found bool) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	i := findIdx(adID)
+	if i < 0 {
+		return "", false
+	}
+	src = store.Ads[i].Src
+	markRemovedLocked(i, removedByAdmin)
+	saveStore()
+	return src, true
+}
+
 // dbDelete permanently removes an ad. Returns the src path and whether it existed.
 func dbDelete(adID string) (src string, found bool) {
 	storeMu.Lock()
@@ -302,17 +861,41 @@ func dbDelete(adID string) (src string, found bool) {
 	return src, true
 }
 
-// dbReorderLive re-assigns sort_order values for live ads.
+// dbReorderLive re-assigns sort_order values for live ads. Listed IDs come
+// first in the given order; any live ads missing from the list (e.g. activated
+// after the dashboard fetched its snapshot) are re-sequenced after them in
+// their previous relative order, so a stale list can never produce duplicate
+// sort_orders.
 func dbReorderLive(orderedIDs []string) error {
 	storeMu.Lock()
 	defer storeMu.Unlock()
-	for pos, id := range orderedIDs {
+
+	pos := 0
+	listed := make(map[string]bool, len(orderedIDs))
+	for _, id := range orderedIDs {
 		i := findIdx(id)
-		if i < 0 {
+		if i < 0 || store.Ads[i].Status != adStatusLive {
 			continue
 		}
+		listed[id] = true
 		store.Ads[i].SortOrder = pos
+		pos++
 	}
+
+	var rest []int
+	for i, r := range store.Ads {
+		if r.Status == adStatusLive && !listed[r.AdID] {
+			rest = append(rest, i)
+		}
+	}
+	sort.Slice(rest, func(a, b int) bool {
+		return store.Ads[rest[a]].SortOrder < store.Ads[rest[b]].SortOrder
+	})
+	for _, i := range rest {
+		store.Ads[i].SortOrder = pos
+		pos++
+	}
+
 	saveStore()
 	return nil
 }
@@ -338,23 +921,106 @@ func dbClearLive() ([]kioskAd, int) {
 	return removed, len(removed)
 }
 
+// dbSnapshotLive returns copies of the live records in sort order, suitable
+// for restoring later with dbRestoreLive.
+func dbSnapshotLive() []adRecord {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	var recs []adRecord
+	for _, r := range store.Ads {
+		if r.Status == adStatusLive {
+			recs = append(recs, r)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].SortOrder < recs[j].SortOrder
+	})
+	return recs
+}
+
+// dbRestoreLive makes the live set exactly match a snapshot taken by
+// dbSnapshotLive: snapshotted records are re-inserted if they were deleted
+// and set live with their old sort_order, while live ads that are not in the
+// snapshot move back to approved.
+func dbRestoreLive(snapshot []adRecord) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	inSnap := make(map[string]bool, len(snapshot))
+	for _, s := range snapshot {
+		inSnap[s.AdID] = true
+	}
+	for i, r := range store.Ads {
+		if r.Status == adStatusLive && !inSnap[r.AdID] {
+			store.Ads[i].Status = adStatusApproved
+			store.Ads[i].SortOrder = 0
+		}
+	}
+	for _, s := range snapshot {
+		if i := findIdx(s.AdID); i >= 0 {
+			store.Ads[i].Status = adStatusLive
+			store.Ads[i].SortOrder = s.SortOrder
+		} else {
+			store.Ads = append(store.Ads, s)
+		}
+	}
+	saveStore()
+}
+
 // dbMoveApprovedToLive moves every approved ad to live and returns the count.
-func dbMoveApprovedToLive() int {
+// The order they are appended in is governed by the activation_order setting:
+// oldest-first (submission order, the default), newest-first, or by name.
+// The max_live_ads cap truncates the batch: only as many ads as fit are
+// activated (in activation order), and the remainder is reported in skipped.
+func dbMoveApprovedToLive() (activated, skipped int) {
+	cap := dbMaxLiveAds()
+
 	storeMu.Lock()
 	defer storeMu.Unlock()
-	base := maxLiveSortOrder()
-	n := 0
+
+	var idxs []int
 	for i, r := range store.Ads {
 		if r.Status == adStatusApproved {
-			n++
-			store.Ads[i].Status = adStatusLive
-			store.Ads[i].SortOrder = base + n
+			idxs = append(idxs, i)
 		}
 	}
-	if n > 0 {
+
+	switch store.Settings[settingActivationOrder] {
+	case activationOrderNewest:
+		sort.Slice(idxs, func(a, b int) bool {
+			return store.Ads[idxs[a]].SubmittedAt > store.Ads[idxs[b]].SubmittedAt
+		})
+	case activationOrderName:
+		sort.Slice(idxs, func(a, b int) bool {
+			return store.Ads[idxs[a]].Name < store.Ads[idxs[b]].Name
+		})
+	default: // oldest-first; SubmittedAt is RFC3339 so lexicographic order works
+		sort.Slice(idxs, func(a, b int) bool {
+			return store.Ads[idxs[a]].SubmittedAt < store.Ads[idxs[b]].SubmittedAt
+		})
+	}
+
+	if cap > 0 {
+		room := cap - liveCountLocked()
+		if room < 0 {
+			room = 0
+		}
+		if len(idxs) > room {
+			skipped = len(idxs) - room
+			idxs = idxs[:room]
+		}
+	}
+
+	base := maxLiveSortOrder()
+	for n, i := range idxs {
+		store.Ads[i].Status = adStatusLive
+		store.Ads[i].ShownOnKiosk = true
+		store.Ads[i].SortOrder = base + n + 1
+	}
+	if len(idxs) > 0 {
 		saveStore()
 	}
-	return n
+	return len(idxs), skipped
 }
 
 // dbBySubmitter returns all ads submitted by the given email, newest first.
@@ -400,15 +1066,418 @@ func dbCounts() map[string]int {
 	return m
 }
 
+// dbMarshalStore returns the full store (ads, playlists, settings) marshalled
+// as JSON — a consistent snapshot taken under the read lock, safe to write to
+// a backup while handlers keep mutating.
+func dbMarshalStore() ([]byte, error) {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return json.MarshalIndent(store, "", "  ")
+}
+
+// ─── Export / import ──────────────────────────────────────────────────────────
+
+// dbExportAll returns a copy of every ad record across all statuses.
+func dbExportAll() []adRecord {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	out := make([]adRecord, len(store.Ads))
+	copy(out, store.Ads)
+	return out
+}
+
+// dbImport upserts the given records by ad_id under a single lock, so a
+// concurrent reader never sees a half-imported pipeline. Returns how many
+// records were created vs updated.
+func dbImport(recs []adRecord) (created, updated int) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	for _, rec := range recs {
+		if rec.AdID == "" {
+			continue
+		}
+		if i := findIdx(rec.AdID); i >= 0 {
+			store.Ads[i] = rec
+			updated++
+		} else {
+			store.Ads = append(store.Ads, rec)
+			created++
+		}
+	}
+	if created > 0 || updated > 0 {
+		saveStore()
+	}
+	return created, updated
+}
+
+// Per-user pipeline quota: caps how many ads one submitter may have in
+// non-terminal stages (submitted/approved/live) at once, so a single user
+// can't swamp the review queue. 0 / unset means unlimited.
+const settingMaxAdsPerUser = "max_ads_per_user"
+
+// dbMaxAdsPerUser returns the configured quota; 0 means unlimited.
+func dbMaxAdsPerUser() int {
+	n, err := strconv.Atoi(dbGetSetting(settingMaxAdsPerUser, ""))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// dbPipelineCount counts a submitter's ads in non-terminal stages. Terminal
+// (denied) ads don't count against the quota. Matches both bare "email" and
+// "Name <email>" submitter formats, like dbBySubmitter.
+func dbPipelineCount(email string) int {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	n := 0
+	for _, r := range store.Ads {
+		switch r.Status {
+		case adStatusSubmitted, adStatusApproved, adStatusLive:
+		default:
+			continue
+		}
+		if r.SubmittedBy == email || strings.Contains(r.SubmittedBy, "<"+email+">") {
+			n++
+		}
+	}
+	return n
+}
+
+// ─── Banned submitters ────────────────────────────────────────────────────────
+
+// dbIsBanned reports whether the given email is on the deny-list.
+// Matching is case-insensitive, like dbBySubmitter.
+func dbIsBanned(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	for _, b := range store.Banned {
+		if strings.ToLower(b.Email) == email {
+			return true
+		}
+	}
+	return false
+}
+
+// dbBanSubmitter adds (or updates the reason on) a deny-list entry and
+// denies any of the submitter's ads still waiting in the submitted queue.
+// Returns how many pending ads were swept.
+func dbBanSubmitter(email, reason string) int {
+	email = strings.ToLower(strings.TrimSpace(email))
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	found := false
+	for i, b := range store.Banned {
+		if strings.ToLower(b.Email) == email {
+			store.Banned[i].Reason = reason
+			found = true
+			break
+		}
+	}
+	if !found {
+		store.Banned = append(store.Banned, bannedRecord{
+			Email:    email,
+			Reason:   reason,
+			BannedAt: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+
+	swept := 0
+	for i, r := range store.Ads {
+		if r.Status == adStatusSubmitted && strings.ToLower(r.OwnerEmail) == email {
+			store.Ads[i].Status = adStatusDenied
+			swept++
+		}
+	}
+	saveStore()
+	return swept
+}
+
+// dbUnbanSubmitter lifts a ban. Returns false if the email wasn't banned.
+func dbUnbanSubmitter(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	for i, b := range store.Banned {
+		if strings.ToLower(b.Email) == email {
+			store.Banned = append(store.Banned[:i], store.Banned[i+1:]...)
+			saveStore()
+			return true
+		}
+	}
+	return false
+}
+
+// dbListBanned returns a copy of the deny-list. Returns an empty (never nil) slice.
+func dbListBanned() []bannedRecord {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	out := make([]bannedRecord, len(store.Banned))
+	copy(out, store.Banned)
+	return out
+}
+
+// ─── Settings ─────────────────────────────────────────────────────────────────
+
+// dbGetSetting returns the stored value for key, or def when unset.
+func dbGetSetting(key, def string) string {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	if v, ok := store.Settings[key]; ok {
+		return v
+	}
+	return def
+}
+
+// dbAllSettings returns a copy of every stored setting.
+func dbAllSettings() map[string]string {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	out := make(map[string]string, len(store.Settings))
+	for k, v := range store.Settings {
+		out[k] = v
+	}
+	return out
+}
+
+// dbSetSetting persists a key/value setting.
+func dbSetSetting(key, value string) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if store.Settings == nil {
+		store.Settings = make(map[string]string)
+	}
+	store.Settings[key] = value
+	saveStore()
+}
+
+// ─── Named playlists ──────────────────────────────────────────────────────────
+
+// dbListPlaylists returns every named playlist. Returns an empty (never nil) slice.
+func dbListPlaylists() []playlistRecord {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	out := make([]playlistRecord, len(store.Playlists))
+	copy(out, store.Playlists)
+	return out
+}
+
+// findPlaylistIdx returns the index of the playlist with the given name, or -1.
+// Caller must hold storeMu.
+func findPlaylistIdx(name string) int {
+	for i, p := range store.Playlists {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// dbSetPlaylist creates or replaces a named playlist with the given ordered IDs.
+func dbSetPlaylist(name string, adIDs []string) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	ids := make([]string, len(adIDs))
+	copy(ids, adIDs)
+	if i := findPlaylistIdx(name); i >= 0 {
+		store.Playlists[i].AdIDs = ids
+	} else {
+		store.Playlists = append(store.Playlists, playlistRecord{Name: name, AdIDs: ids})
+	}
+	saveStore()
+}
+
+// dbDeletePlaylist removes a named playlist. Returns true when it existed.
+func dbDeletePlaylist(name string) bool {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	i := findPlaylistIdx(name)
+	if i < 0 {
+		return false
+	}
+	store.Playlists = append(store.Playlists[:i], store.Playlists[i+1:]...)
+	saveStore()
+	return true
+}
+
+// dbPlaylistAds resolves a named playlist to its ads in stored order, silently
+// skipping IDs whose ad has since been deleted. The second return reports
+// whether the playlist exists at all.
+func dbPlaylistAds(name string) ([]kioskAd, bool) {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	i := findPlaylistIdx(name)
+	if i < 0 {
+		return nil, false
+	}
+	out := make([]kioskAd, 0, len(store.Playlists[i].AdIDs))
+	for _, id := range store.Playlists[i].AdIDs {
+		if j := findIdx(id); j >= 0 {
+			out = append(out, recToAd(store.Ads[j]))
+		}
+	}
+	return out, true
+}
+
+// ─── Day-part schedule ────────────────────────────────────────────────────────
+
+// dbListSchedule returns the schedule entries in evaluation order.
+// Returns an empty (never nil) slice.
+func dbListSchedule() []scheduleRecord {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	out := make([]scheduleRecord, len(store.Schedule))
+	copy(out, store.Schedule)
+	return out
+}
+
+// dbSetSchedule replaces the whole schedule.
+func dbSetSchedule(entries []scheduleRecord) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store.Schedule = make([]scheduleRecord, len(entries))
+	copy(store.Schedule, entries)
+	saveStore()
+}
+
+// parseClock converts "HH:MM" to minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("bad time %q: want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// dbScheduledPlaylist returns the playlist name the schedule selects for the
+// given wall-clock time, if any. Entries are evaluated in stored order and
+// the first match wins, so overlaps resolve predictably: put the more
+// specific range first. Entries that fail to parse are skipped.
+func dbScheduledPlaylist(now time.Time) (string, bool) {
+	minute := now.Hour()*60 + now.Minute()
+	for _, e := range dbListSchedule() {
+		start, err1 := parseClock(e.Start)
+		end, err2 := parseClock(e.End)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		var match bool
+		if start <= end {
+			match = minute >= start && minute < end
+		} else {
+			// Wraps midnight, e.g. 22:00–06:00.
+			match = minute >= start || minute < end
+		}
+		if match {
+			return e.Playlist, true
+		}
+	}
+	return "", false
+}
+
+// ─── Admin sessions ───────────────────────────────────────────────────────────
+
+// dbAddSession persists a hashed admin token with its expiry.
+func dbAddSession(tokenHash string, expiry time.Time) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store.Sessions = append(store.Sessions, sessionRecord{
+		TokenHash: tokenHash,
+		ExpiresAt: expiry.UTC().Format(time.RFC3339),
+	})
+	saveStore()
+}
+
+// dbDeleteSession removes a session by token hash. No-op when absent.
+func dbDeleteSession(tokenHash string) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	for i, s := range store.Sessions {
+		if s.TokenHash == tokenHash {
+			store.Sessions = append(store.Sessions[:i], store.Sessions[i+1:]...)
+			saveStore()
+			return
+		}
+	}
+}
+
+// dbSessionValid reports whether a session with this token hash exists and
+// has not expired. Expired rows are left for dbPruneSessions to reap.
+func dbSessionValid(tokenHash string) bool {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	for _, s := range store.Sessions {
+		if s.TokenHash != tokenHash {
+			continue
+		}
+		exp, err := time.Parse(time.RFC3339, s.ExpiresAt)
+		return err == nil && time.Now().Before(exp)
+	}
+	return false
+}
+
+// dbPruneSessions drops expired sessions, returning how many were removed.
+func dbPruneSessions() int {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	now := time.Now()
+	kept := store.Sessions[:0]
+	for _, s := range store.Sessions {
+		if exp, err := time.Parse(time.RFC3339, s.ExpiresAt); err == nil && now.Before(exp) {
+			kept = append(kept, s)
+		}
+	}
+	removed := len(store.Sessions) - len(kept)
+	if removed > 0 {
+		store.Sessions = kept
+		saveStore()
+	}
+	return removed
+}
+
 // ─── Media file cleanup ───────────────────────────────────────────────────────
 
+// mediaRefLocked bumps the reference count for a src that points at a local
+// media file. No-op for remote URLs. Caller holds the storeMu write lock.
+func mediaRefLocked(src string) {
+	if !strings.HasPrefix(src, "/media/") {
+		return
+	}
+	if store.Media == nil {
+		store.Media = map[string]int{}
+	}
+	store.Media[filepath.Base(strings.TrimPrefix(src, "/media/"))]++
+}
+
 // deleteMediaFile removes the cached local file for an ad whose src is a
 // /media/ relative path. Safe to call when src is empty or a remote URL.
+// Content-hash files can back several ads at once, so the file is only
+// removed from disk once its last reference is gone; files written before
+// refcounting existed are untracked and deleted immediately, as before.
 func deleteMediaFile(src string) {
 	if mediaDir == "" || !strings.HasPrefix(src, "/media/") {
 		return
 	}
 	rel := strings.TrimPrefix(src, "/media/")
+	file := filepath.Base(filepath.FromSlash(rel))
+	storeMu.Lock()
+	if n, tracked := store.Media[file]; tracked {
+		if n > 1 {
+			store.Media[file] = n - 1
+			saveStore()
+			storeMu.Unlock()
+			log.Printf("DB: media file %q still referenced by %d ad(s) — kept", file, n-1)
+			return
+		}
+		delete(store.Media, file)
+		saveStore()
+	}
+	storeMu.Unlock()
 	path := filepath.Join(mediaDir, filepath.FromSlash(rel))
 	if err := os.Remove(path); err == nil {
 		log.Printf("DB: removed media file %q", path)
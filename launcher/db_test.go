@@ -0,0 +1,521 @@
+package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// resetStore points the store at a throwaway file and seeds it with the given ads.
+func resetStore(t *testing.T, ads []adRecord) {
+	t.Helper()
+	storeMu.Lock()
+	storePath = filepath.Join(t.TempDir(), "ads.json")
+	store = adStore{Ads: ads}
+	storeMu.Unlock()
+}
+
+func TestMediaLocalFlag(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "local", Src: "/media/local.png", Status: adStatusLive},
+		{AdID: "remote", Src: "https://example.com/a.png", Status: adStatusLive},
+	})
+	for _, c := range []struct {
+		id   string
+		want bool
+	}{{"local", true}, {"remote", false}} {
+		ad, ok := dbGetAd(c.id)
+		if !ok {
+			t.Fatalf("ad %q not found", c.id)
+		}
+		if ad.MediaLocal != c.want {
+			t.Fatalf("ad %q: mediaLocal = %v, want %v", c.id, ad.MediaLocal, c.want)
+		}
+	}
+}
+
+// TestLiveMutationsConcurrent hammers reorder, activate, and clear from
+// multiple goroutines (run with -race) and asserts the final state is
+// consistent: every live ad has a unique sort_order.
+func TestLiveMutationsConcurrent(t *testing.T) {
+	var ads []adRecord
+	for i := 0; i < 20; i++ {
+		ads = append(ads, adRecord{
+			AdID:        string(rune('a' + i)),
+			Name:        string(rune('a' + i)),
+			Status:      adStatusApproved,
+			SubmittedAt: "2024-01-01T00:00:00Z",
+		})
+	}
+	resetStore(t, ads)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				switch (n + j) % 4 {
+				case 0:
+					dbMoveApprovedToLive()
+				case 1:
+					dbReorderLive([]string{"a", "b", "c", "d"})
+				case 2:
+					dbMoveToLive(string(rune('a' + j%20)))
+				case 3:
+					dbClearLive()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	live := dbLiveOrdered()
+	seen := make(map[int]string, len(live))
+	for _, ad := range live {
+		// dbLiveOrdered sorts by sort_order; look the order back up to check
+		// uniqueness across the final live set.
+		storeMu.RLock()
+		order := store.Ads[findIdx(ad.ID)].SortOrder
+		storeMu.RUnlock()
+		if prev, dup := seen[order]; dup {
+			t.Fatalf("duplicate sort_order %d shared by %q and %q", order, prev, ad.ID)
+		}
+		seen[order] = ad.ID
+	}
+}
+
+func TestMoveApprovedToLiveOrdering(t *testing.T) {
+	seed := func() []adRecord {
+		return []adRecord{
+			{AdID: "b", Name: "Zeta", Status: adStatusApproved, SubmittedAt: "2024-01-02T00:00:00Z"},
+			{AdID: "a", Name: "Midway", Status: adStatusApproved, SubmittedAt: "2024-01-03T00:00:00Z"},
+			{AdID: "c", Name: "Alpha", Status: adStatusApproved, SubmittedAt: "2024-01-01T00:00:00Z"},
+		}
+	}
+
+	cases := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"c", "b", "a"}}, // default: oldest-first
+		{activationOrderOldest, []string{"c", "b", "a"}},
+		{activationOrderNewest, []string{"a", "b", "c"}},
+		{activationOrderName, []string{"c", "a", "b"}}, // Alpha, Midway, Zeta
+	}
+
+	for _, c := range cases {
+		t.Run("order="+c.order, func(t *testing.T) {
+			resetStore(t, seed())
+			if c.order != "" {
+				dbSetSetting(settingActivationOrder, c.order)
+			}
+			if n, _ := dbMoveApprovedToLive(); n != 3 {
+				t.Fatalf("expected 3 activated, got %d", n)
+			}
+			live := dbLiveOrdered()
+			if len(live) != len(c.want) {
+				t.Fatalf("expected %d live ads, got %d", len(c.want), len(live))
+			}
+			for i, ad := range live {
+				if ad.ID != c.want[i] {
+					t.Fatalf("position %d: got %q want %q", i, ad.ID, c.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateStore(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "old-live", Status: adStatusLive, SubmittedBy: "Pat Doe <pat@example.com>", SubmittedAt: "2024-01-01T00:00:00Z"},
+		{AdID: "no-stamp", Status: adStatusSubmitted, SubmittedBy: "sam@example.com"},
+	})
+
+	storeMu.Lock()
+	migrateStore()
+	storeMu.Unlock()
+
+	rec, _ := dbGetAd("old-live")
+	if rec.OwnerName != "Pat Doe" || !rec.ShownOnKiosk {
+		t.Fatalf("live record not migrated: %+v", rec)
+	}
+	storeMu.RLock()
+	second := store.Ads[1]
+	storeMu.RUnlock()
+	if second.OwnerEmail != "sam@example.com" || second.SubmittedAt == "" {
+		t.Fatalf("bare-email record not migrated: %+v", second)
+	}
+
+	// Running again must be a no-op.
+	storeMu.Lock()
+	before := store.Ads[0]
+	migrateStore()
+	after := store.Ads[0]
+	storeMu.Unlock()
+	if before != after {
+		t.Fatalf("migration is not idempotent: %+v vs %+v", before, after)
+	}
+}
+
+func TestPlaylistETag(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "a", Name: "A", AdType: "image", Src: "/media/a.png", Status: adStatusLive, SortOrder: 1},
+	})
+
+	rr := httptest.NewRecorder()
+	handlePlaylist(rr, httptest.NewRequest("GET", "/api/playlist", nil))
+	if rr.Code != 200 {
+		t.Fatalf("got %d, want 200", rr.Code)
+	}
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	// Unchanged playlist → 304 with no body re-sent.
+	req := httptest.NewRequest("GET", "/api/playlist", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handlePlaylist(rr, req)
+	if rr.Code != 304 {
+		t.Fatalf("got %d, want 304", rr.Code)
+	}
+
+	// Any live-set mutation invalidates the tag.
+	signalPlaylistChanged()
+	rr = httptest.NewRecorder()
+	handlePlaylist(rr, req)
+	if rr.Code != 200 {
+		t.Fatalf("after mutation got %d, want 200", rr.Code)
+	}
+	if rr.Header().Get("ETag") == etag {
+		t.Fatal("ETag should change after a mutation")
+	}
+}
+
+func TestDefaultDurationSetting(t *testing.T) {
+	resetStore(t, nil)
+	cases := []struct {
+		setting string
+		want    int
+	}{
+		{"", fallbackDefaultDurationMs},
+		{"not-a-number", fallbackDefaultDurationMs},
+		{"15000", 15000},
+		{"50", 1000},     // clamped up
+		{"99999", 30000}, // clamped down
+	}
+	for _, c := range cases {
+		if c.setting != "" {
+			dbSetSetting(settingDefaultDurationMs, c.setting)
+		}
+		if got := dbDefaultDurationMs(); got != c.want {
+			t.Errorf("setting %q: got %d, want %d", c.setting, got, c.want)
+		}
+	}
+}
+
+func TestPlaybackErrorDemotion(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "flaky", Status: adStatusLive},
+	})
+
+	for n := 1; n < fallbackBrokenThreshold; n++ {
+		count, demoted, found := dbRecordPlaybackError("flaky", "decode error")
+		if !found || demoted || count != n {
+			t.Fatalf("report %d: count=%d demoted=%v found=%v", n, count, demoted, found)
+		}
+	}
+
+	// The report that reaches the threshold demotes the ad out of live.
+	count, demoted, found := dbRecordPlaybackError("flaky", "decode error")
+	if !found || !demoted || count != fallbackBrokenThreshold {
+		t.Fatalf("threshold report: count=%d demoted=%v found=%v", count, demoted, found)
+	}
+	rec, _ := dbGetAd("flaky")
+	if rec.Broken != true {
+		t.Fatal("expected ad to be flagged broken")
+	}
+	storeMu.RLock()
+	status := store.Ads[0].Status
+	storeMu.RUnlock()
+	if status != adStatusApproved {
+		t.Fatalf("status = %q, want %q", status, adStatusApproved)
+	}
+
+	// Further reports must not demote again (already out of live).
+	if _, demoted, _ := dbRecordPlaybackError("flaky", "still broken"); demoted {
+		t.Fatal("demotion should be a one-shot per incident")
+	}
+
+	if _, _, found := dbRecordPlaybackError("nope", "x"); found {
+		t.Fatal("unknown ad should not be found")
+	}
+}
+
+func TestLiveCap(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "old", Status: adStatusLive, SortOrder: 1},
+		{AdID: "new", Status: adStatusLive, SortOrder: 2},
+		{AdID: "next", Status: adStatusApproved, SubmittedAt: "2024-01-01T00:00:00Z"},
+		{AdID: "later", Status: adStatusApproved, SubmittedAt: "2024-01-02T00:00:00Z"},
+	})
+	dbSetSetting(settingMaxLiveAds, "2")
+
+	// Default behavior at the cap: reject the activation.
+	ok, evicted, capHit, conflict := dbMoveToLive("next")
+	if ok || evicted != "" || !capHit || conflict {
+		t.Fatalf("reject mode: ok=%v evicted=%q capHit=%v conflict=%v", ok, evicted, capHit, conflict)
+	}
+
+	// Bulk activation truncates to fit the cap.
+	if activated, skipped := dbMoveApprovedToLive(); activated != 0 || skipped != 2 {
+		t.Fatalf("bulk at cap: activated=%d skipped=%d", activated, skipped)
+	}
+
+	// Evict mode demotes the oldest live ad to make room.
+	dbSetSetting(settingLiveCapBehavior, liveCapEvict)
+	ok, evicted, capHit, conflict = dbMoveToLive("next")
+	if !ok || evicted != "old" || capHit || conflict {
+		t.Fatalf("evict mode: ok=%v evicted=%q capHit=%v conflict=%v", ok, evicted, capHit, conflict)
+	}
+	live := dbLiveOrdered()
+	if len(live) != 2 || live[0].ID != "new" || live[1].ID != "next" {
+		t.Fatalf("unexpected live set after eviction: %+v", live)
+	}
+}
+
+func TestBannedSubmitters(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "pending", Status: adStatusSubmitted, OwnerEmail: "spam@example.com"},
+		{AdID: "live", Status: adStatusLive, OwnerEmail: "spam@example.com"},
+		{AdID: "other", Status: adStatusSubmitted, OwnerEmail: "ok@example.com"},
+	})
+
+	if dbIsBanned("spam@example.com") {
+		t.Fatal("nobody should be banned initially")
+	}
+	if swept := dbBanSubmitter("Spam@Example.com", "abuse"); swept != 1 {
+		t.Fatalf("swept = %d, want 1 (only the pending ad)", swept)
+	}
+	if !dbIsBanned("SPAM@example.com") {
+		t.Fatal("ban should match case-insensitively")
+	}
+	storeMu.RLock()
+	pendingStatus, liveStatus := store.Ads[0].Status, store.Ads[1].Status
+	storeMu.RUnlock()
+	if pendingStatus != adStatusDenied || liveStatus != adStatusLive {
+		t.Fatalf("pending=%q live=%q — only submitted ads should be swept", pendingStatus, liveStatus)
+	}
+
+	if !dbUnbanSubmitter("spam@example.com") {
+		t.Fatal("unban should succeed")
+	}
+	if dbUnbanSubmitter("spam@example.com") {
+		t.Fatal("second unban should report not-banned")
+	}
+	if dbIsBanned("spam@example.com") {
+		t.Fatal("ban should be lifted")
+	}
+}
+
+// TestPipelineConcurrent drives the whole submit→approve→activate→clear
+// pipeline from several goroutines at once. Its value is under -race: every
+// mutation path must go through storeMu, and this catches any handler that
+// grows a lock-free shortcut.
+func TestPipelineConcurrent(t *testing.T) {
+	resetStore(t, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				id := fmt.Sprintf("ad-%d-%d", n, j)
+				switch (n + j) % 6 {
+				case 0:
+					_ = dbSaveAd(kioskAd{ID: id, Name: id, Type: "image", SubmittedBy: "User <u@example.com>"}, "")
+				case 1:
+					dbSetStatus(id, adStatusApproved)
+				case 2:
+					dbMoveToLive(id)
+				case 3:
+					dbRecordPlaybackError(id, "boom")
+				case 4:
+					dbSetSetting(settingActivationOrder, activationOrderNewest)
+					_ = dbPipelineCount("u@example.com")
+				case 5:
+					dbClearLive()
+					_, _ = dbMoveApprovedToLive()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The store must still round-trip cleanly after the storm.
+	if _, err := dbMarshalStore(); err != nil {
+		t.Fatalf("store corrupted: %v", err)
+	}
+}
+
+func TestGuardedTransitions(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "ad", Status: adStatusSubmitted},
+	})
+
+	ok, conflict, _ := dbTransition("ad", adStatusSubmitted, adStatusApproved)
+	if !ok || conflict {
+		t.Fatalf("first approve: ok=%v conflict=%v", ok, conflict)
+	}
+	// Second admin approving the same ad loses deterministically.
+	ok, conflict, current := dbTransition("ad", adStatusSubmitted, adStatusApproved)
+	if ok || !conflict || current != adStatusApproved {
+		t.Fatalf("second approve: ok=%v conflict=%v current=%q", ok, conflict, current)
+	}
+
+	// Activation only moves approved ads…
+	ok, _, _, conflict = dbMoveToLive("ad")
+	if !ok || conflict {
+		t.Fatalf("activate approved: ok=%v conflict=%v", ok, conflict)
+	}
+	// …so a duplicate activation conflicts instead of double-appending.
+	ok, _, _, conflict = dbMoveToLive("ad")
+	if ok || !conflict {
+		t.Fatalf("duplicate activate: ok=%v conflict=%v", ok, conflict)
+	}
+
+	if ok, conflict, _ := dbTransition("missing", adStatusSubmitted, adStatusApproved); ok || conflict {
+		t.Fatal("unknown ad should be not-found, not conflict")
+	}
+}
+
+func TestScheduledPlaylist(t *testing.T) {
+	resetStore(t, nil)
+	storeMu.Lock()
+	store.Schedule = []scheduleRecord{
+		{Start: "08:00", End: "12:00", Playlist: "morning"},
+		{Start: "08:00", End: "22:00", Playlist: "daytime"}, // overlaps; later so it loses 08–12
+		{Start: "22:00", End: "06:00", Playlist: "overnight"},
+	}
+	storeMu.Unlock()
+
+	at := func(hhmm string) time.Time {
+		tm, err := time.Parse("15:04", hhmm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	cases := []struct {
+		clock string
+		want  string // "" means no match
+	}{
+		{"08:00", "morning"},
+		{"11:59", "morning"},
+		{"12:00", "daytime"}, // end is exclusive, first match wins before noon
+		{"21:59", "daytime"},
+		{"23:30", "overnight"}, // wraps midnight
+		{"03:00", "overnight"},
+		{"06:30", ""},
+	}
+	for _, c := range cases {
+		got, ok := dbScheduledPlaylist(at(c.clock))
+		if c.want == "" {
+			if ok {
+				t.Fatalf("%s: expected no match, got %q", c.clock, got)
+			}
+			continue
+		}
+		if !ok || got != c.want {
+			t.Fatalf("%s: got %q (ok=%v), want %q", c.clock, got, ok, c.want)
+		}
+	}
+
+	// Unparseable entries are skipped, not fatal.
+	storeMu.Lock()
+	store.Schedule = []scheduleRecord{{Start: "garbage", End: "12:00", Playlist: "x"}}
+	storeMu.Unlock()
+	if _, ok := dbScheduledPlaylist(at("10:00")); ok {
+		t.Fatal("malformed entry should not match")
+	}
+}
+
+func TestDeleteMediaFileRefcount(t *testing.T) {
+	resetStore(t, nil)
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	shared := filepath.Join(mediaDir, "abc123.png")
+	if err := os.WriteFile(shared, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Two ads referencing the same content-hash file.
+	for _, id := range []string{"ref-a", "ref-b"} {
+		if err := dbSaveAd(kioskAd{ID: id, Name: id, Type: "image", Src: "/media/abc123.png"}, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleteMediaFile("/media/abc123.png")
+	if _, err := os.Stat(shared); err != nil {
+		t.Fatal("file deleted while still referenced")
+	}
+	deleteMediaFile("/media/abc123.png")
+	if _, err := os.Stat(shared); !os.IsNotExist(err) {
+		t.Fatal("file should be gone after last reference")
+	}
+
+	// Untracked files (stored before refcounting) are deleted immediately.
+	legacy := filepath.Join(mediaDir, "legacy.png")
+	if err := os.WriteFile(legacy, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deleteMediaFile("/media/legacy.png")
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatal("untracked file should be deleted immediately")
+	}
+}
+
+func TestAdminSessionsPersistAndExpire(t *testing.T) {
+	resetStore(t, nil)
+
+	token := generateToken()
+	dbAddSession(tokenHash(token), time.Now().Add(time.Hour))
+	if !isValidToken(token) {
+		t.Fatal("fresh session should validate")
+	}
+	if isValidToken("not-the-token") {
+		t.Fatal("unknown token validated")
+	}
+
+	// Only the hash is at rest.
+	storeMu.RLock()
+	stored := store.Sessions[0].TokenHash
+	storeMu.RUnlock()
+	if stored == token {
+		t.Fatal("raw token persisted to store")
+	}
+
+	// Expired sessions stop validating and get reaped by the sweep.
+	expired := generateToken()
+	dbAddSession(tokenHash(expired), time.Now().Add(-time.Minute))
+	if isValidToken(expired) {
+		t.Fatal("expired session validated")
+	}
+	if n := dbPruneSessions(); n != 1 {
+		t.Fatalf("pruned %d sessions, want 1", n)
+	}
+
+	dbDeleteSession(tokenHash(token))
+	if isValidToken(token) {
+		t.Fatal("logged-out session validated")
+	}
+}
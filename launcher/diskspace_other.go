@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+// diskFreeBytes is unsupported off Windows; the preflight in checkAndApply
+// treats an error as "cannot tell" and proceeds.
+func diskFreeBytes(path string) (uint64, error) {
+	return 0, errors.New("disk space query not supported on this platform")
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadResumesWithRange(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789"), 100) // 1000 bytes
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var gotRange, gotIfRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		gotIfRange = r.Header.Get("If-Range")
+		// http.ServeContent implements Range/If-Range/Accept-Ranges for us.
+		http.ServeContent(w, r, "clip.mp4", modTime, bytes.NewReader(payload))
+	}))
+	defer srv.Close()
+
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	// Simulate an earlier interrupted download: the first 400 bytes made it,
+	// with the validator sidecar recording what they came from.
+	part := filepath.Join(mediaDir, "resume-ad.mp4.part")
+	if err := os.WriteFile(part, payload[:400], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(part+".validator", []byte(modTime.Format(http.TimeFormat)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(payload)
+	wantName := hashName(sum[:], ".mp4")
+	src := downloadToMedia("resume-ad", srv.URL+"/clip.mp4")
+	if src != "/media/"+wantName {
+		t.Fatalf("got src %q, want /media/%s", src, wantName)
+	}
+	if gotRange != "bytes=400-" {
+		t.Fatalf("expected a resume request, got Range %q", gotRange)
+	}
+	if gotIfRange != modTime.Format(http.TimeFormat) {
+		t.Fatalf("expected the stored validator as If-Range, got %q", gotIfRange)
+	}
+	final, err := os.ReadFile(filepath.Join(mediaDir, wantName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(final, payload) {
+		t.Fatalf("assembled file is %d bytes, want %d (content mismatch)", len(final), len(payload))
+	}
+	if _, err := os.Stat(part); !os.IsNotExist(err) {
+		t.Fatal(".part file should be renamed away on completion")
+	}
+	if _, err := os.Stat(part + ".validator"); !os.IsNotExist(err) {
+		t.Fatal("validator sidecar should be removed on completion")
+	}
+}
+
+func TestDownloadRestartsWithoutRangeSupport(t *testing.T) {
+	payload := []byte("full-content-no-ranges")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Ignore Range entirely — always a 200 with the whole body.
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	part := filepath.Join(mediaDir, "norange-ad.png.part")
+	if err := os.WriteFile(part, []byte("stale-partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(part+".validator", []byte(`"etag-1"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(payload)
+	wantName := hashName(sum[:], ".png")
+	if src := downloadToMedia("norange-ad", srv.URL+"/pic.png"); src != "/media/"+wantName {
+		t.Fatalf("got src %q, want /media/%s", src, wantName)
+	}
+	final, err := os.ReadFile(filepath.Join(mediaDir, wantName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(final, payload) {
+		t.Fatalf("expected a clean restart, got %q", final)
+	}
+}
+
+func TestDownloadDeduplicatesByHash(t *testing.T) {
+	payload := []byte("shared promo image bytes")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	first := downloadToMedia("ad-one", srv.URL+"/promo.png")
+	second := downloadToMedia("ad-two", srv.URL+"/promo.png")
+	if first != second {
+		t.Fatalf("identical content got different srcs: %q vs %q", first, second)
+	}
+	entries, _ := os.ReadDir(mediaDir)
+	if len(entries) != 1 {
+		t.Fatalf("media dir has %d files, want 1 (deduped)", len(entries))
+	}
+}
+
+func TestDownloadDiscardsPartialWithoutValidator(t *testing.T) {
+	payload := []byte("authoritative fresh content")
+	var gotRange string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	// A partial with no validator sidecar: there is no way to prove the
+	// remote file hasn't changed, so resuming could splice two versions.
+	part := filepath.Join(mediaDir, "noval-ad.png.part")
+	if err := os.WriteFile(part, []byte("bytes-from-old-version"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(payload)
+	wantName := hashName(sum[:], ".png")
+	if src := downloadToMedia("noval-ad", srv.URL+"/pic.png"); src != "/media/"+wantName {
+		t.Fatalf("got src %q, want /media/%s", src, wantName)
+	}
+	if gotRange != "" {
+		t.Fatalf("unvalidatable partial should not be resumed, got Range %q", gotRange)
+	}
+	final, err := os.ReadFile(filepath.Join(mediaDir, wantName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(final, payload) {
+		t.Fatalf("expected a clean restart, got %q", final)
+	}
+}
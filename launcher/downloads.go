@@ -0,0 +1,131 @@
+package main
+
+// downloads.go — bounded queue in front of media downloads.
+//
+// A bulk approval used to fire one goroutine per ad, and a dozen concurrent
+// video downloads would saturate the kiosk's link and make the ad on screen
+// stutter. Downloads now funnel through a small worker pool so they queue
+// instead of competing; the playback path never notices.
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultDownloadConcurrency is how many media downloads may run at once.
+// Two keeps bulk approvals moving without starving the stream on screen.
+const defaultDownloadConcurrency = 2
+
+type downloadJob struct {
+	adID string
+	src  string
+}
+
+// downloadQueue holds pending jobs; the buffer is generous because jobs are
+// tiny and a full queue means falling back to an unpooled goroutine.
+var downloadQueue = make(chan downloadJob, 256)
+
+// downloadQueueDepth counts jobs queued or in flight, for stats.
+var downloadQueueDepth atomic.Int32
+
+// downloadConcurrency returns the worker count (DOWNLOAD_CONCURRENCY), or
+// the default.
+func downloadConcurrency() int {
+	v := strings.TrimSpace(os.Getenv("DOWNLOAD_CONCURRENCY"))
+	if v == "" {
+		return defaultDownloadConcurrency
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("Media: invalid DOWNLOAD_CONCURRENCY %q — using %d", v, defaultDownloadConcurrency)
+		return defaultDownloadConcurrency
+	}
+	return n
+}
+
+// startDownloadWorkers launches the pool. Called once from main().
+func startDownloadWorkers() {
+	n := downloadConcurrency()
+	for i := 0; i < n; i++ {
+		go func() {
+			for job := range downloadQueue {
+				runDownload(job)
+				downloadQueueDepth.Add(-1)
+			}
+		}()
+	}
+	log.Printf("Media: %d download worker(s) started", n)
+}
+
+// queueMediaDownload hands a remote src to the pool. If the queue is somehow
+// full it falls back to a direct goroutine — caching the media matters more
+// than the concurrency bound in that corner.
+func queueMediaDownload(adID, src string) {
+	downloadQueueDepth.Add(1)
+	select {
+	case downloadQueue <- downloadJob{adID: adID, src: src}:
+	default:
+		log.Printf("Media: download queue full — fetching %q unpooled", adID)
+		go func() {
+			runDownload(downloadJob{adID: adID, src: src})
+			downloadQueueDepth.Add(-1)
+		}()
+	}
+}
+
+func runDownload(job downloadJob) {
+	newSrc := downloadToMedia(job.adID, job.src)
+	if newSrc != job.src {
+		dbUpdateSrc(job.adID, newSrc)
+		log.Printf("Media: ad %q cached as %s", job.adID, newSrc)
+	}
+	maybeAutoDuration(job.adID)
+}
+
+// maybeAutoDuration fills in the real media length for video ads submitted
+// with durationMode "auto", now that the file is local and can be probed.
+// When probing isn't possible (no ffprobe, remote src, broken file) the
+// submitted/default duration simply stands.
+func maybeAutoDuration(adID string) {
+	ad, ok := dbGetAd(adID)
+	if !ok || ad.Type != "video" || ad.DurationMode != "auto" {
+		return
+	}
+	if !strings.HasPrefix(ad.Src, "/media/") {
+		return
+	}
+	ms, err := probeVideoDurationMs(filepath.Join(mediaDir, filepath.Base(ad.Src)))
+	if err != nil {
+		log.Printf("Media: ad %q duration probe failed (keeping %d ms): %v", adID, ad.DurationMs, err)
+		return
+	}
+	if dbSetDuration(adID, ms) {
+		log.Printf("Media: ad %q duration set to %d ms from probed video length", adID, ms)
+	}
+}
+
+// probeVideoDurationMs asks ffprobe for the container duration, in
+// milliseconds. Requires ffprobe on PATH, like videoThumbnail does ffmpeg.
+func probeVideoDurationMs(path string) (int, error) {
+	ffprobe, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe not installed")
+	}
+	out, err := exec.Command(ffprobe, "-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1", path).Output()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe: %v", err)
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil || secs <= 0 {
+		return 0, fmt.Errorf("ffprobe returned %q", strings.TrimSpace(string(out)))
+	}
+	return int(secs * 1000), nil
+}
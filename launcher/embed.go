@@ -0,0 +1,100 @@
+package main
+
+// embed.go — YouTube/Vimeo "embed" ads.
+//
+// Submitters keep pasting watch-page links as "video" ads, which fail on the
+// kiosk because they aren't direct media files. An ad submitted with type
+// "embed" instead carries a watch URL: the launcher validates the host
+// against a small allowlist, extracts the video ID, and synthesizes a
+// full-bleed iframe HTML document the kiosk already knows how to render.
+// The watch URL is kept as the ad's original_url for the admin view.
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Video-ID shapes. YouTube IDs are 11 chars of [A-Za-z0-9_-]; Vimeo IDs are
+// plain numbers. Being strict here is what makes the synthesized iframe safe
+// to emit without escaping.
+var (
+	youtubeIDRe = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDRe   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// normalizeEmbedURL turns a pasted watch URL into a player (embed) URL, or
+// returns an error when the host isn't allowlisted or no video ID can be
+// found. Accepted shapes:
+//
+//	youtube.com/watch?v=ID    youtu.be/ID    youtube.com/shorts/ID
+//	youtube.com/embed/ID      vimeo.com/ID   player.vimeo.com/video/ID
+func normalizeEmbedURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("embed URL must be http(s), got %q", u.Scheme)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch host {
+	case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+		var id string
+		switch {
+		case len(segs) > 0 && segs[0] == "watch":
+			id = u.Query().Get("v")
+		case len(segs) == 2 && (segs[0] == "shorts" || segs[0] == "embed" || segs[0] == "live"):
+			id = segs[1]
+		}
+		if !youtubeIDRe.MatchString(id) {
+			return "", fmt.Errorf("no YouTube video ID in %q", raw)
+		}
+		// nocookie player, muted autoplay on loop — kiosks have no audience
+		// interaction, and browsers block unmuted autoplay anyway.
+		return "https://www.youtube-nocookie.com/embed/" + id +
+			"?autoplay=1&mute=1&loop=1&controls=0&playlist=" + id, nil
+
+	case "youtu.be":
+		if len(segs) != 1 || !youtubeIDRe.MatchString(segs[0]) {
+			return "", fmt.Errorf("no YouTube video ID in %q", raw)
+		}
+		id := segs[0]
+		return "https://www.youtube-nocookie.com/embed/" + id +
+			"?autoplay=1&mute=1&loop=1&controls=0&playlist=" + id, nil
+
+	case "vimeo.com", "player.vimeo.com":
+		var id string
+		switch {
+		case len(segs) == 1:
+			id = segs[0]
+		case len(segs) == 2 && segs[0] == "video":
+			id = segs[1]
+		}
+		if !vimeoIDRe.MatchString(id) {
+			return "", fmt.Errorf("no Vimeo video ID in %q", raw)
+		}
+		return "https://player.vimeo.com/video/" + id +
+			"?autoplay=1&muted=1&loop=1&background=1", nil
+	}
+
+	return "", fmt.Errorf("embed host %q not allowed (YouTube and Vimeo only)", host)
+}
+
+// embedAdHTML wraps a normalized player URL in a full-bleed iframe document.
+// embedURL is launcher-built from a validated video ID, never raw user input.
+func embedAdHTML(embedURL string) string {
+	return `<!DOCTYPE html>
+<html>
+<head><style>
+  html, body { margin: 0; height: 100%; background: #000; overflow: hidden; }
+  iframe { width: 100%; height: 100%; border: 0; }
+</style></head>
+<body><iframe src="` + embedURL + `" allow="autoplay; fullscreen" allowfullscreen></iframe></body>
+</html>
+`
+}
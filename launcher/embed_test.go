@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEmbedURL(t *testing.T) {
+	const yt = "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ"
+	cases := []struct {
+		name string
+		in   string
+		want string // prefix of the normalized URL; "" means expect an error
+	}{
+		{"watch url", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", yt},
+		{"watch url extra params", "https://youtube.com/watch?v=dQw4w9WgXcQ&t=42s&list=PL1", yt},
+		{"short link", "https://youtu.be/dQw4w9WgXcQ", yt},
+		{"shorts", "https://www.youtube.com/shorts/dQw4w9WgXcQ", yt},
+		{"already embed", "https://www.youtube.com/embed/dQw4w9WgXcQ", yt},
+		{"mobile host", "https://m.youtube.com/watch?v=dQw4w9WgXcQ", yt},
+		{"vimeo", "https://vimeo.com/76979871", "https://player.vimeo.com/video/76979871"},
+		{"vimeo player", "https://player.vimeo.com/video/76979871", "https://player.vimeo.com/video/76979871"},
+
+		{"bad host", "https://evil.example.com/watch?v=dQw4w9WgXcQ", ""},
+		{"lookalike host", "https://youtube.com.evil.example/watch?v=dQw4w9WgXcQ", ""},
+		{"watch without id", "https://www.youtube.com/watch", ""},
+		{"malformed id", "https://youtu.be/short", ""},
+		{"channel page", "https://www.youtube.com/@somechannel", ""},
+		{"non-numeric vimeo", "https://vimeo.com/about", ""},
+		{"javascript scheme", "javascript:alert(1)", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := normalizeEmbedURL(c.in)
+			if c.want == "" {
+				if err == nil {
+					t.Fatalf("expected rejection, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(got, c.want) {
+				t.Fatalf("got %q, want prefix %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestEmbedAdHTML(t *testing.T) {
+	html := embedAdHTML("https://player.vimeo.com/video/1")
+	for _, want := range []string{`<iframe src="https://player.vimeo.com/video/1"`, "allow=\"autoplay"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in synthesized page:\n%s", want, html)
+		}
+	}
+}
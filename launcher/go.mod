@@ -1,11 +1,15 @@
 module shell-ads-launcher
 
-go 1.24.0
+go 1.25.0
 
-require github.com/minio/selfupdate v0.6.0
+require (
+	github.com/minio/selfupdate v0.6.0
+	golang.org/x/image v0.45.0
+	golang.org/x/net v0.58.0
+	golang.org/x/sys v0.47.0
+)
 
 require (
 	aead.dev/minisign v0.2.0 // indirect
-	golang.org/x/crypto v0.0.0-20211209193657-4570a0811e8b // indirect
-	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
+	golang.org/x/crypto v0.55.0 // indirect
 )
@@ -0,0 +1,175 @@
+package main
+
+// htmlads.go — HTML ads with uploaded asset files.
+//
+// Plain HTML ads are self-contained strings, which rules out anything with a
+// local image or font. This path accepts a multipart submission carrying the
+// page markup plus its asset files, stores each asset under /media/, rewrites
+// the HTML's relative references to the cached paths, and writes the finished
+// page the same way embed ads are synthesized — so the kiosk just renders a
+// local mini-page.
+//
+// Multipart layout (on top of the common "ad" field handleSubmitMultipart
+// decodes):
+//
+//	field "html"   the page markup, referencing assets by bare filename
+//	files "asset"  zero or more asset files (images / fonts / css)
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Per-asset and per-ad bounds. Assets are small page furniture, not media —
+// anything bigger belongs in a normal image/video ad.
+const (
+	htmlAssetMaxBytes = 5 << 20
+	htmlAssetMaxCount = 20
+)
+
+// htmlAssetExts is the allowlist of asset types an HTML ad may bundle.
+// Script files are deliberately absent: the kiosk renders these pages with
+// full chrome privileges, and nothing submitted by the public runs there.
+var htmlAssetExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
+	".svg": true, ".css": true, ".woff": true, ".woff2": true, ".ttf": true,
+	".otf": true,
+}
+
+// submitHTMLAd handles the HTML branch of a multipart submission: one HTML
+// ad plus its asset files. Form parsing and the ban/quota gates have already
+// run in handleSubmitMultipart.
+func submitHTMLAd(w http.ResponseWriter, r *http.Request, d dashAd) {
+	html := r.FormValue("html")
+	if strings.TrimSpace(html) == "" {
+		http.Error(w, `{"error":"missing html form field"}`, http.StatusBadRequest)
+		return
+	}
+
+	files := r.MultipartForm.File["asset"]
+	if len(files) > htmlAssetMaxCount {
+		msg := fmt.Sprintf(`{"error":"too many assets (max %d)"}`, htmlAssetMaxCount)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	stored := make([]string, 0, len(files))
+	cleanup := func() {
+		for _, name := range stored {
+			os.Remove(filepath.Join(mediaDir, name))
+		}
+	}
+	for _, fh := range files {
+		name, err := storeHTMLAsset(d.ID, fh)
+		if err != nil {
+			cleanup()
+			http.Error(w, fmt.Sprintf(`{"error":"asset %q: %s"}`, fh.Filename, err), http.StatusBadRequest)
+			return
+		}
+		stored = append(stored, name)
+		html = rewriteAssetRefs(html, path.Base(fh.Filename), "/media/"+name)
+	}
+
+	pageName := safeFileName(d.ID) + ".html"
+	if err := os.WriteFile(filepath.Join(mediaDir, pageName), []byte(html), 0o644); err != nil {
+		log.Printf("Submit: html ad %q: write page failed: %v", d.ID, err)
+		cleanup()
+		http.Error(w, `{"error":"could not store page"}`, http.StatusInternalServerError)
+		return
+	}
+
+	durMs := d.DurationSec * 1000
+	if durMs <= 0 {
+		durMs = dbDefaultDurationMs()
+	}
+	const minMs, maxMs = 1000, 30000
+	if durMs < minMs {
+		durMs = minMs
+	}
+	if durMs > maxMs {
+		durMs = maxMs
+	}
+	ad := kioskAd{
+		ID:          d.ID,
+		Name:        d.Name,
+		Type:        "html",
+		DurationMs:  durMs,
+		Src:         "/media/" + pageName,
+		Transition:  adTransition{Enter: normalizeTransition(d.Enter), Exit: normalizeTransition(d.Exit)},
+		SubmittedBy: d.SubmittedBy,
+	}
+	if err := dbSaveAd(ad, ""); err != nil {
+		log.Printf("Submit: failed to save html ad %q: %v", d.ID, err)
+		cleanup()
+		os.Remove(filepath.Join(mediaDir, pageName))
+		http.Error(w, `{"error":"could not be saved"}`, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Submit: html ad %q queued with %d asset(s)", d.ID, len(stored))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok":       true,
+		"accepted": 1,
+		"results": []map[string]any{
+			{"id": d.ID, "accepted": true},
+		},
+	})
+}
+
+// storeHTMLAsset validates one uploaded asset and writes it under mediaDir,
+// returning the stored file name. Names are prefixed with the ad ID so assets
+// from different ads can't collide or overwrite each other.
+func storeHTMLAsset(adID string, fh *multipart.FileHeader) (string, error) {
+	base := path.Base(fh.Filename)
+	ext := strings.ToLower(filepath.Ext(base))
+	if !htmlAssetExts[ext] {
+		return "", fmt.Errorf("type %s not allowed", ext)
+	}
+	if fh.Size > htmlAssetMaxBytes {
+		return "", fmt.Errorf("too large (max %d MB)", htmlAssetMaxBytes>>20)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return "", fmt.Errorf("unreadable")
+	}
+	defer f.Close()
+
+	name := safeFileName(adID) + "-asset-" + safeFileName(strings.TrimSuffix(base, filepath.Ext(base))) + ext
+	dst, err := os.Create(filepath.Join(mediaDir, name))
+	if err != nil {
+		return "", fmt.Errorf("could not store")
+	}
+	// Enforce the size cap on bytes actually read, not just the declared size.
+	_, err = io.Copy(dst, io.LimitReader(f, htmlAssetMaxBytes+1))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filepath.Join(mediaDir, name))
+		return "", fmt.Errorf("could not store")
+	}
+	if fi, err := os.Stat(filepath.Join(mediaDir, name)); err == nil && fi.Size() > htmlAssetMaxBytes {
+		os.Remove(filepath.Join(mediaDir, name))
+		return "", fmt.Errorf("too large (max %d MB)", htmlAssetMaxBytes>>20)
+	}
+	return name, nil
+}
+
+// rewriteAssetRefs points the page's references to an uploaded file at its
+// cached /media/ path. Only delimited occurrences are touched — quoted
+// attribute values and CSS url(...) — so prose that happens to mention the
+// filename is left alone.
+func rewriteAssetRefs(html, orig, cached string) string {
+	for _, q := range []string{`"`, `'`} {
+		html = strings.ReplaceAll(html, q+orig+q, q+cached+q)
+	}
+	return strings.ReplaceAll(html, "("+orig+")", "("+cached+")")
+}
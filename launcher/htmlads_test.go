@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// multipartSubmit builds a multipart /api/submit-ads request for one HTML ad.
+func multipartSubmit(t *testing.T, adJSON, html string, assets map[string][]byte) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("ad", adJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.WriteField("html", html); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range assets {
+		fw, err := mw.CreateFormFile("asset", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mw.Close()
+	req := httptest.NewRequest("POST", "/api/submit-ads", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestSubmitHTMLAdWithAssets(t *testing.T) {
+	resetStore(t, nil)
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	html := `<html><body style="background:url(bg.png)"><img src="logo.png">See logo.png here</body></html>`
+	req := multipartSubmit(t,
+		`{"id":"html-1","name":"Promo","type":"html","durationSec":8,"submittedBy":"Pat <pat@example.com>"}`,
+		html,
+		map[string][]byte{"logo.png": []byte("fakepng"), "bg.png": []byte("fakebg")})
+	rec := httptest.NewRecorder()
+	handleSubmitAds(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	ad, found := dbGetAd("html-1")
+	if !found {
+		t.Fatal("ad not saved")
+	}
+	if ad.Type != "html" || ad.Src != "/media/html-1.html" {
+		t.Fatalf("saved ad = %+v", ad)
+	}
+	page, err := os.ReadFile(filepath.Join(mediaDir, "html-1.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(page)
+	if !strings.Contains(got, `src="/media/html-1-asset-logo.png"`) {
+		t.Errorf("img reference not rewritten: %s", got)
+	}
+	if !strings.Contains(got, `url(/media/html-1-asset-bg.png)`) {
+		t.Errorf("css reference not rewritten: %s", got)
+	}
+	// Prose mentioning the filename is not a reference and stays untouched.
+	if !strings.Contains(got, "See logo.png here") {
+		t.Errorf("prose was rewritten: %s", got)
+	}
+	for _, name := range []string{"html-1-asset-logo.png", "html-1-asset-bg.png"} {
+		if _, err := os.Stat(filepath.Join(mediaDir, name)); err != nil {
+			t.Errorf("asset %s not stored: %v", name, err)
+		}
+	}
+}
+
+func TestSubmitHTMLAdRejectsBadAssets(t *testing.T) {
+	resetStore(t, nil)
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	req := multipartSubmit(t,
+		`{"id":"html-2","name":"Sneaky","type":"html","submittedBy":"Pat <pat@example.com>"}`,
+		`<script src="evil.js"></script>`,
+		map[string][]byte{"evil.js": []byte("alert(1)")})
+	rec := httptest.NewRecorder()
+	handleSubmitAds(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if _, found := dbGetAd("html-2"); found {
+		t.Error("rejected ad was saved anyway")
+	}
+	if entries, _ := os.ReadDir(mediaDir); len(entries) != 0 {
+		t.Errorf("media dir not clean after rejection: %v", entries)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+// logs.go — bounded in-memory log rings for remote debugging.
+//
+// Field kiosks have no console anyone can see, so the kiosk process's
+// stdout/stderr are teed into a ring of the last few hundred lines and served
+// through the admin API — both as a JSON snapshot and as an SSE stream that
+// pushes new lines live. No RDP needed to read a crash trace.
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"encoding/json"
+)
+
+const kioskLogLines = 500
+
+// kioskLogs receives everything the kiosk writes to stdout/stderr.
+var kioskLogs = newLogRing(kioskLogLines)
+
+// launcherLogs receives the launcher's own log package output (update stages,
+// restarts, admin actions) — wired into log.SetOutput in main() alongside the
+// console so nothing disappears from stderr.
+var launcherLogs = newLogRing(kioskLogLines)
+
+// logRing is a fixed-capacity line buffer that is safe for concurrent
+// writers and readers, with optional fan-out to live subscribers.
+type logRing struct {
+	mu      sync.Mutex
+	lines   []string
+	max     int
+	partial bytes.Buffer // trailing bytes not yet terminated by \n
+	subs    map[chan string]struct{}
+}
+
+func newLogRing(max int) *logRing {
+	return &logRing{max: max, subs: make(map[chan string]struct{})}
+}
+
+// Write implements io.Writer so a ring can sit in a MultiWriter or serve as a
+// child process's Stdout. Input is split into lines; an unterminated tail is
+// held back until its newline arrives.
+func (l *logRing) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partial.Write(p)
+	for {
+		data := l.partial.Bytes()
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(bytes.TrimRight(data[:i], "\r"))
+		l.partial.Next(i + 1)
+		l.appendLocked(line)
+	}
+	return len(p), nil
+}
+
+func (l *logRing) appendLocked(line string) {
+	l.lines = append(l.lines, line)
+	if len(l.lines) > l.max {
+		l.lines = l.lines[len(l.lines)-l.max:]
+	}
+	for ch := range l.subs {
+		select {
+		case ch <- line:
+		default: // slow subscriber — drop rather than block the writer
+		}
+	}
+}
+
+// snapshot returns a copy of the buffered lines, oldest first.
+func (l *logRing) snapshot() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]string, len(l.lines))
+	copy(out, l.lines)
+	return out
+}
+
+// subscribe registers a live-line channel. The returned cancel must be called
+// or the subscription leaks.
+func (l *logRing) subscribe() (<-chan string, func()) {
+	ch := make(chan string, 64)
+	l.mu.Lock()
+	l.subs[ch] = struct{}{}
+	l.mu.Unlock()
+	return ch, func() {
+		l.mu.Lock()
+		delete(l.subs, ch)
+		l.mu.Unlock()
+	}
+}
+
+// handleAdminKioskLogs returns the buffered kiosk output, oldest first.
+func handleAdminKioskLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"lines": kioskLogs.snapshot()})
+}
+
+// handleAdminLauncherLogs returns the launcher's own buffered log output,
+// oldest first — the first place to look when an update failed on a machine
+// nobody can physically reach.
+func handleAdminLauncherLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"lines": launcherLogs.snapshot()})
+}
+
+// handleAdminKioskLogStream pushes kiosk output as Server-Sent Events: the
+// buffered backlog first, then new lines as they arrive, until the client
+// disconnects.
+func handleAdminKioskLogStream(w http.ResponseWriter, r *http.Request) {
+	fl, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, `{"error":"streaming unsupported"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch, cancel := kioskLogs.subscribe()
+	defer cancel()
+
+	for _, line := range kioskLogs.snapshot() {
+		fmt.Fprintf(w, "data: %s\n\n", line)
+	}
+	fl.Flush()
+
+	for {
+		select {
+		case line := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", line)
+			fl.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLogRingWriteAndBound(t *testing.T) {
+	ring := newLogRing(3)
+
+	// Partial writes only become lines once their newline arrives.
+	fmt.Fprint(ring, "first ")
+	fmt.Fprint(ring, "line\nsecond line\n")
+	got := ring.snapshot()
+	if len(got) != 2 || got[0] != "first line" || got[1] != "second line" {
+		t.Fatalf("unexpected lines: %q", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(ring, "line-%d\n", i)
+	}
+	got = ring.snapshot()
+	if len(got) != 3 || got[0] != "line-7" || got[2] != "line-9" {
+		t.Fatalf("ring not bounded to newest 3 lines: %q", got)
+	}
+}
+
+func TestLogRingSubscribe(t *testing.T) {
+	ring := newLogRing(10)
+	ch, cancel := ring.subscribe()
+	defer cancel()
+
+	fmt.Fprint(ring, "hello\r\n")
+	select {
+	case line := <-ch:
+		if line != "hello" {
+			t.Fatalf("got %q, want %q", line, "hello")
+		}
+	default:
+		t.Fatal("expected a pushed line")
+	}
+}
@@ -2,19 +2,27 @@ package main
 
 import (
 	"archive/zip"
+	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"embed"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
+	mrand "math/rand"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -44,24 +52,58 @@ const (
 	// Timing
 	updateCheckDelay    = 30 * time.Second
 	updateCheckInterval = 1 * time.Hour
-	kioskRestartDelay   = 3 * time.Second
-	postKillDelay       = 2 * time.Second
+)
+
+// Restart pacing. The defaults suit the original kiosk hardware; slower
+// machines need longer for Windows to release the binary lock after a kill,
+// or kiosk.exe replacement hits sharing violations during applyUpdate.
+// Overridable via KIOSK_RESTART_DELAY / POST_KILL_DELAY (Go durations, read
+// once at startup in main()).
+var (
+	kioskRestartDelay = 3 * time.Second
+	postKillDelay     = 2 * time.Second
+)
+
+// Frozen-renderer watchdog: a hung web view keeps the process alive and the
+// heartbeat flowing, but reports 0 FPS. If that persists for fpsStallWindow
+// the kiosk gets restarted. Tuned via FPS_STALL_WINDOW (a Go duration) and
+// FPS_STALL_THRESHOLD (frames per second, default 0), read once in main().
+var (
+	fpsStallWindow    = 30 * time.Second
+	fpsStallThreshold = 0.0
 )
 
 // BuildNumber is stamped at compile time via -ldflags "-X main.BuildNumber=<n>".
 // Stays "dev" for local runs.
 var BuildNumber string = "dev"
 
+// updateChannel selects which releases this kiosk tracks. "stable" (default)
+// ignores pre-releases; "beta" also considers them, so a couple of kiosks can
+// pilot new builds before a fleet-wide rollout.
+var updateChannel = func() string {
+	switch c := strings.ToLower(strings.TrimSpace(os.Getenv("UPDATE_CHANNEL"))); c {
+	case "", "stable":
+		return "stable"
+	case "beta":
+		return "beta"
+	default:
+		log.Printf("Updater: unknown UPDATE_CHANNEL %q — using stable", c)
+		return "stable"
+	}
+}()
+
 // ─── GitHub API types ─────────────────────────────────────────────────────────
 
 type ghRelease struct {
-	TagName string    `json:"tag_name"`
-	HTMLURL string    `json:"html_url"`
-	Assets  []ghAsset `json:"assets"`
+	TagName    string    `json:"tag_name"`
+	HTMLURL    string    `json:"html_url"`
+	Prerelease bool      `json:"prerelease"`
+	Assets     []ghAsset `json:"assets"`
 }
 
 type ghAsset struct {
 	Name               string `json:"name"`
+	Size               int64  `json:"size"`
 	BrowserDownloadURL string `json:"browser_download_url"`
 }
 
@@ -75,15 +117,33 @@ type adTransition struct {
 }
 
 type kioskAd struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	Type        string       `json:"type"`
-	DurationMs  int          `json:"durationMs"`
-	Src         string       `json:"src,omitempty"`
-	HTML        string       `json:"html,omitempty"`
-	Transition  adTransition `json:"transition"`
-	SubmittedBy string       `json:"submittedBy,omitempty"`
-	SubmittedAt string       `json:"submittedAt,omitempty"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	DurationMs int    `json:"durationMs"`
+	// DurationMode: "" / "fixed" plays for DurationMs; "auto" (videos only)
+	// means DurationMs tracks the probed media length.
+	DurationMode string       `json:"durationMode,omitempty"`
+	Src          string       `json:"src,omitempty"`
+	HTML         string       `json:"html,omitempty"`
+	Transition   adTransition `json:"transition"`
+	SubmittedBy  string       `json:"submittedBy,omitempty"`
+	SubmittedAt  string       `json:"submittedAt,omitempty"`
+	// MediaLocal is computed (never stored): true when the ad is served from
+	// the local /media/ cache rather than hot-linking a remote URL, so the
+	// admin UI can flag remote-dependent ads that are at risk of breaking.
+	MediaLocal bool `json:"mediaLocal"`
+	// Lifecycle context persisted in the store so the admin view keeps its
+	// submitter/timestamp columns across launcher restarts.
+	OwnerName    string `json:"ownerName,omitempty"`
+	ApprovedAt   string `json:"approvedAt,omitempty"`
+	ShownOnKiosk bool   `json:"shownOnKiosk,omitempty"`
+	IsDefault    bool   `json:"isDefault,omitempty"`
+	// Playback-failure bookkeeping so the admin UI can flag broken ads.
+	ErrorCount  int    `json:"errorCount,omitempty"`
+	LastError   string `json:"lastError,omitempty"`
+	LastErrorAt string `json:"lastErrorAt,omitempty"`
+	Broken      bool   `json:"broken,omitempty"`
 }
 
 // dashAd is the shape the React dashboard POSTs to /api/force-ads.
@@ -94,6 +154,27 @@ type dashAd struct {
 	URL         string `json:"url"`
 	DurationSec int    `json:"durationSec"`
 	SubmittedBy string `json:"submittedBy"`
+	// DurationMode "auto" asks for the video's real length instead of a
+	// fixed duration; ignored for non-video types.
+	DurationMode string `json:"durationMode,omitempty"`
+	Enter        string `json:"enter,omitempty"` // optional transition (fade/slide/zoom/none)
+	Exit         string `json:"exit,omitempty"`
+}
+
+// validTransitions is the allowlist of per-ad transition effects the kiosk
+// frontend knows how to render.
+var validTransitions = map[string]bool{
+	"fade": true, "slide": true, "zoom": true, "none": true,
+}
+
+// normalizeTransition returns the given transition name when allowlisted,
+// falling back to "fade" for anything absent or unknown.
+func normalizeTransition(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if validTransitions[name] {
+		return name
+	}
+	return "fade"
 }
 
 // uploadRequest is the JSON body for POST /api/upload-media.
@@ -108,10 +189,30 @@ type uploadRequest struct {
 // 3 GB covers the 2 GB raw file limit with base64 overhead (~33 %).
 const maxUploadBodyBytes = 3 * 1024 * 1024 * 1024
 
+// maxPreviewBytes mirrors the 2 GB raw file limit for the admin preview proxy.
+const maxPreviewBytes = 2 * 1024 * 1024 * 1024
+
+// mediaTransport clones the default transport with a header deadline, so
+// mediaClient hangs only while bytes are actually flowing.
+func mediaTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = 30 * time.Second
+	return t
+}
+
 // ─── Global state ─────────────────────────────────────────────────────────────
 
 var (
-	httpClient = &http.Client{Timeout: 60 * time.Second}
+	// apiClient is for small metadata calls — GitHub release lookups,
+	// moderation, signature fetches. These should fail fast rather than
+	// hang the caller for a minute.
+	apiClient = &http.Client{Timeout: 15 * time.Second}
+
+	// mediaClient is for bulk media and update-bundle downloads. No overall
+	// deadline — a large video on a slow field link can legitimately take
+	// longer than any fixed timeout — but a peer that stalls before sending
+	// headers is still cut off at the transport.
+	mediaClient = &http.Client{Transport: mediaTransport()}
 
 	// kiosk process management
 	kioskMu        sync.Mutex
@@ -145,16 +246,145 @@ var (
 // launcherStartedAt records when the launcher process started.
 var launcherStartedAt = time.Now()
 
+// navPollTimeout is how long handleNavPoll blocks waiting for a command.
+// Shorter feels snappier on flaky networks; longer cuts request volume on
+// good ones. Tuned via NAV_POLL_TIMEOUT (a Go duration), read once at
+// startup in main().
+var navPollTimeout = 2 * time.Second
+
 // activeUploads counts /api/upload-media requests currently in flight.
 var activeUploads atomic.Int32
 
+// kioskLastContact is the unix-nano time the kiosk frontend last hit one of
+// the kiosk-facing endpoints (nav-poll, playlist). Zero until first contact.
+// It is the liveness signal behind /api/health: the process being alive says
+// nothing about whether the web view is actually running and polling.
+var kioskLastContact atomic.Int64
+
+func touchKioskContact() {
+	kioskLastContact.Store(time.Now().UnixNano())
+}
+
 // ─── Admin auth ───────────────────────────────────────────────────────────────
 
 const adminPassword = "theworldstops"
 
-type tokenEntry struct{ expiry time.Time }
+// Admin token lifetime. Overridable via ADMIN_TOKEN_TTL (a Go duration, read
+// once at startup in main()) for operators who want shorter-lived sessions —
+// or longer ones for a kiosk in a locked room. Capped so a typo can't mint
+// effectively-immortal tokens.
+const (
+	defaultAdminTokenTTL = 24 * time.Hour
+	maxAdminTokenTTL     = 30 * 24 * time.Hour
+)
+
+var adminTokenTTL = defaultAdminTokenTTL
+
+// Brute-force guard on the password gate: a per-IP sliding window of login
+// attempts. Five tries a minute is generous for a human and useless for a
+// dictionary run.
+const (
+	authWindow      = time.Minute
+	authMaxAttempts = 5
+)
+
+var (
+	authAttemptsMu sync.Mutex
+	authAttempts   = map[string][]time.Time{}
+)
+
+// trustedProxyNets is parsed from TRUSTED_PROXIES at startup. When the
+// dashboard sits behind the shellnews tunnel, every RemoteAddr is the
+// tunnel's loopback and per-IP limiting is useless — but blindly trusting
+// X-Forwarded-For from anyone is a spoofing vector, so forwarding headers
+// are honoured only for connections that come from one of these networks.
+var trustedProxyNets []*net.IPNet
+
+// parseTrustedProxies parses a comma-separated CIDR list (bare IPs are
+// treated as /32 or /128). Invalid entries are logged and skipped.
+func parseTrustedProxies(spec string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			if ip := net.ParseIP(part); ip != nil {
+				bits := 32
+				if ip.To4() == nil {
+					bits = 128
+				}
+				part = fmt.Sprintf("%s/%d", ip, bits)
+			}
+		}
+		_, n, err := net.ParseCIDR(part)
+		if err != nil {
+			log.Printf("Proxy: invalid TRUSTED_PROXIES entry %q — skipped", part)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+func fromTrustedProxy(remoteIP string) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trustedProxyNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the real client address for rate limiting and audit logs.
+// For direct connections that's RemoteAddr; when the peer is a configured
+// trusted proxy, the rightmost X-Forwarded-For entry (the address the proxy
+// itself saw) or X-Real-IP is used instead.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !fromTrustedProxy(host) {
+		return host
+	}
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		parts := strings.Split(xff, ",")
+		if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+			return ip
+		}
+	}
+	if rip := strings.TrimSpace(r.Header.Get("X-Real-IP")); rip != "" {
+		return rip
+	}
+	return host
+}
 
-var adminTokens sync.Map // string → tokenEntry
+// authRateLimited records one attempt from ip and reports whether it has
+// exceeded the window. Entries age out as they leave the window, so the map
+// stays small without a background sweeper.
+func authRateLimited(ip string) bool {
+	now := time.Now()
+	authAttemptsMu.Lock()
+	defer authAttemptsMu.Unlock()
+	recent := authAttempts[ip][:0]
+	for _, t := range authAttempts[ip] {
+		if now.Sub(t) < authWindow {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= authMaxAttempts {
+		authAttempts[ip] = recent
+		return true
+	}
+	authAttempts[ip] = append(recent, now)
+	return false
+}
 
 // ─── Update status (polled by admin dashboard for live progress) ──────────────
 
@@ -163,14 +393,37 @@ type updateStageInfo struct {
 	Message string `json:"message"`
 	Current string `json:"current"` // this binary's build label
 	Latest  string `json:"latest"`  // latest GitHub release tag (empty until known)
-	ErrMsg  string `json:"error,omitempty"`
+	Channel string `json:"channel"` // update channel this kiosk tracks (stable|beta)
+	// Progress is the download percentage (0–100) while Stage is
+	// "downloading"; it resets to 0 on every stage change.
+	Progress int    `json:"progress"`
+	ErrMsg   string `json:"error,omitempty"`
+	// NextCheckAt is when updateLoop will next poll GitHub (RFC3339; empty
+	// for dev builds, which never check). Filled in at serve time.
+	NextCheckAt string `json:"nextCheckAt,omitempty"`
+	// PreviousBuild is the build that ran before the last self-update, with
+	// UpdatedAt saying when it was replaced. Filled in at serve time.
+	PreviousBuild string `json:"previousBuild,omitempty"`
+	UpdatedAt     string `json:"updatedAt,omitempty"`
 }
 
 var (
 	updateStatusMu  sync.RWMutex
 	updateStatusVal = updateStageInfo{Stage: "idle", Current: BuildNumber}
+
+	nextUpdateCheckMu sync.RWMutex
+	nextUpdateCheckAt time.Time
 )
 
+// setNextUpdateCheck records when updateLoop will wake next, so the
+// dashboard can say "next check in 40 min" instead of leaving operators
+// guessing whether to trigger one manually.
+func setNextUpdateCheck(at time.Time) {
+	nextUpdateCheckMu.Lock()
+	nextUpdateCheckAt = at
+	nextUpdateCheckMu.Unlock()
+}
+
 func setUpdateStage(stage, message, latest, errMsg string) {
 	updateStatusMu.Lock()
 	updateStatusVal = updateStageInfo{
@@ -178,31 +431,64 @@ func setUpdateStage(stage, message, latest, errMsg string) {
 		Message: message,
 		Current: BuildNumber,
 		Latest:  latest,
+		Channel: updateChannel,
 		ErrMsg:  errMsg,
 	}
 	updateStatusMu.Unlock()
 	log.Printf("Update [%s] %s", stage, message)
 }
 
+// setUpdateProgress updates only the progress percentage of the current
+// stage, so the dashboard's update-status poll can render a real bar.
+func setUpdateProgress(pct int) {
+	updateStatusMu.Lock()
+	updateStatusVal.Progress = pct
+	updateStatusMu.Unlock()
+}
+
+// progressReader counts bytes as they pass through and reports the percentage
+// of total via onProgress — at most once per whole percentage point, so the
+// status mutex isn't hammered on every Read.
+type progressReader struct {
+	r          io.Reader
+	total      int64
+	read       int64
+	lastPct    int
+	onProgress func(pct int)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.total > 0 {
+		if pct := int(p.read * 100 / p.total); pct > p.lastPct {
+			p.lastPct = pct
+			p.onProgress(pct)
+		}
+	}
+	return n, err
+}
+
 func generateToken() string {
 	b := make([]byte, 16)
 	_, _ = rand.Read(b)
 	return hex.EncodeToString(b)
 }
 
+// tokenHash is the at-rest form of an admin token. Sessions live in the
+// store (so they survive the launcher's own restarts, self-updates
+// included), and only the hash is persisted — the raw token exists nowhere
+// but the admin's browser.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func isValidToken(token string) bool {
 	if token == "" {
 		return false
 	}
-	v, ok := adminTokens.Load(token)
-	if !ok {
-		return false
-	}
-	if time.Now().After(v.(tokenEntry).expiry) {
-		adminTokens.Delete(token)
-		return false
-	}
-	return true
+	return dbSessionValid(tokenHash(token))
 }
 
 func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
@@ -216,18 +502,88 @@ func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// cleanupTokens purges expired tokens every 15 minutes so the sync.Map
-// doesn't grow unboundedly when many admins log in over a long run.
+// cleanupTokens purges expired sessions every 15 minutes so the store
+// doesn't grow unboundedly when many admins log in over a long run. The
+// idempotency cache rides along on the same sweep.
 func cleanupTokens() {
 	for {
 		time.Sleep(15 * time.Minute)
 		now := time.Now()
-		adminTokens.Range(func(k, v any) bool {
-			if now.After(v.(tokenEntry).expiry) {
-				adminTokens.Delete(k)
+		if n := dbPruneSessions(); n > 0 {
+			log.Printf("Admin: pruned %d expired session(s)", n)
+		}
+		idemMu.Lock()
+		for k, e := range idemCache {
+			if now.Sub(e.at) > idemTTL {
+				delete(idemCache, k)
 			}
-			return true
-		})
+		}
+		idemMu.Unlock()
+	}
+}
+
+// ─── Idempotency ──────────────────────────────────────────────────────────────
+//
+// A flaky network makes the dashboard retry POST /api/submit-ads; dbSaveAd
+// dedupes on ad_id, but only when the client reuses IDs. With an
+// Idempotency-Key header the first response is cached for a few minutes and
+// replayed verbatim on retry, so duplicates can't form even when the client
+// regenerates IDs. Entries are reaped by cleanupTokens.
+
+const idemTTL = 5 * time.Minute
+
+type idemEntry struct {
+	status int
+	body   []byte
+	at     time.Time
+}
+
+var (
+	idemMu    sync.Mutex
+	idemCache = map[string]idemEntry{}
+)
+
+// captureWriter tees the response so it can be cached for replay.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (c *captureWriter) WriteHeader(code int) {
+	c.status = code
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *captureWriter) Write(b []byte) (int, error) {
+	c.buf.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// withIdempotency replays a cached response for a repeated Idempotency-Key;
+// requests without the header pass straight through.
+func withIdempotency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+		if key == "" {
+			next(w, r)
+			return
+		}
+		idemMu.Lock()
+		e, hit := idemCache[key]
+		idemMu.Unlock()
+		if hit && time.Since(e.at) <= idemTTL {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Idempotency-Replayed", "true")
+			w.WriteHeader(e.status)
+			_, _ = w.Write(e.body)
+			return
+		}
+		cw := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+		next(cw, r)
+		idemMu.Lock()
+		idemCache[key] = idemEntry{status: cw.status, body: cw.buf.Bytes(), at: time.Now()}
+		idemMu.Unlock()
 	}
 }
 
@@ -235,34 +591,122 @@ func cleanupTokens() {
 
 func main() {
 	log.SetFlags(log.Ltime | log.Lshortfile)
+	// Mirror our own log output into the admin-visible ring buffer while
+	// keeping the console copy.
+	log.SetOutput(io.MultiWriter(os.Stderr, launcherLogs))
 	log.Printf("Shell Ads Launcher — build=%s", BuildNumber)
 	log.Printf("Admin: password is 'theworldstops'")
 
 	exeDir := exeDirectory()
 	log.Printf("Base directory: %s", exeDir)
 
+	if spec := os.Getenv("TRUSTED_PROXIES"); spec != "" {
+		trustedProxyNets = parseTrustedProxies(spec)
+		log.Printf("Proxy: honouring forwarding headers from %d trusted network(s)", len(trustedProxyNets))
+	}
+
+	if v := strings.TrimSpace(os.Getenv("ADMIN_TOKEN_TTL")); v != "" {
+		switch d, err := time.ParseDuration(v); {
+		case err != nil || d <= 0:
+			log.Printf("Admin: invalid ADMIN_TOKEN_TTL %q — keeping %s", v, adminTokenTTL)
+		case d > maxAdminTokenTTL:
+			adminTokenTTL = maxAdminTokenTTL
+			log.Printf("Admin: ADMIN_TOKEN_TTL %s exceeds the %s cap — clamped", d, maxAdminTokenTTL)
+		default:
+			adminTokenTTL = d
+			log.Printf("Admin: token TTL set to %s", d)
+		}
+	}
+
+	for _, tune := range []struct {
+		env    string
+		target *time.Duration
+	}{
+		{"KIOSK_RESTART_DELAY", &kioskRestartDelay},
+		{"POST_KILL_DELAY", &postKillDelay},
+		{"FPS_STALL_WINDOW", &fpsStallWindow},
+	} {
+		v := strings.TrimSpace(os.Getenv(tune.env))
+		if v == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			*tune.target = d
+			log.Printf("Timing: %s set to %s", tune.env, d)
+		} else {
+			log.Printf("Timing: invalid %s %q — keeping %s", tune.env, v, *tune.target)
+		}
+	}
+
+	if v := strings.TrimSpace(os.Getenv("FPS_STALL_THRESHOLD")); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			fpsStallThreshold = f
+			log.Printf("Watchdog: FPS stall threshold set to %.1f", f)
+		} else {
+			log.Printf("Watchdog: invalid FPS_STALL_THRESHOLD %q — keeping %.1f", v, fpsStallThreshold)
+		}
+	}
+
+	if v := strings.TrimSpace(os.Getenv("NAV_POLL_TIMEOUT")); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			navPollTimeout = d
+			log.Printf("Nav: poll timeout set to %s", d)
+		} else {
+			log.Printf("Nav: invalid NAV_POLL_TIMEOUT %q — keeping %s", v, navPollTimeout)
+		}
+	}
+
+	// Refuse to run twice: a double-clicked second launcher, or the update
+	// re-exec racing the still-exiting old process, would otherwise die on
+	// the :6969 bind with a confusing panic. Exit cleanly instead.
+	if err := acquireInstanceLock(exeDir); err != nil {
+		log.Printf("Launcher: another instance appears to be running (%v) — exiting", err)
+		return
+	}
+
 	// 1. Initialise the media cache directory (must happen before initDB and
 	//    before any ad handler can call downloadToMedia or deleteMediaFile).
-	mediaDir = filepath.Join(exeDir, "media")
+	//    MEDIA_DIR overrides the default location next to the binary, and
+	//    MEDIA_MAX_BYTES caps total usage via the periodic LRU cleanup.
+	mediaDir = strings.TrimSpace(os.Getenv("MEDIA_DIR"))
+	if mediaDir == "" {
+		mediaDir = filepath.Join(exeDir, "media")
+	}
 	_ = os.MkdirAll(mediaDir, 0o755)
+	go mediaCleanupLoop()
 
-	// 2. Bootstrap PocketBase (SQLite persistence).  This is synchronous —
-	//    all handlers are safe to use only after this returns.
+	// 2. Bootstrap the JSON store.  This is synchronous — all handlers are
+	//    safe to use only after this returns. A corrupt store must not take
+	//    the process down with a panic: we keep running in a visible degraded
+	//    mode (maintenance page, kiosk supervision still active) so the
+	//    operator can fix or restore ads.json.
 	if err := initDB(); err != nil {
-		log.Fatalf("Database: %v", err)
+		log.Printf("Database: %v — running degraded, API disabled", err)
 	}
 
-	// 3. Serve the embedded React dashboard — no Node/npm needed on the machine
+	// 3. Daily store snapshots under backups/ (and on-demand via the admin API).
+	backupsDir = filepath.Join(exeDir, "backups")
+	_ = os.MkdirAll(backupsDir, 0o755)
+	go backupLoop()
+
+	// 4. Serve the embedded React dashboard — no Node/npm needed on the machine
 	go serveDash()
 
-	// 4. Periodically purge expired admin tokens (prevents unbounded growth
+	// 5. Periodically purge expired admin tokens (prevents unbounded growth
 	//    when many admins log in and out over a long run).
 	go cleanupTokens()
 
-	// 5. Launch the kiosk and restart it if it ever exits unexpectedly
-	go monitorKiosk(filepath.Join(exeDir, kioskBin))
+	// 6. Launch the kiosk and restart it if it ever exits unexpectedly.
+	//    First reap any kiosk a crashed or updated previous launcher left
+	//    behind, so two processes never fight over the screen.
+	kioskPath := filepath.Join(exeDir, kioskBin)
+	if n := killOrphanKiosks(kioskPath); n > 0 {
+		log.Printf("Kiosk: killed %d orphaned kiosk process(es) from a previous run", n)
+	}
+	go monitorKiosk(kioskPath)
+	go heartbeatWatch()
 
-	// 6. Auto-restart the kiosk every 3 hours to prevent memory / rendering drift.
+	// 7. Auto-restart the kiosk every 3 hours to prevent memory / rendering drift.
 	//    Before restarting, we wait (up to 90 s) for any in-progress uploads to
 	//    finish so users are never mid-upload when Bernard cycles.  This is
 	//    identical to pressing "Restart Bernard" in the admin dashboard: stopKiosk
@@ -300,17 +744,27 @@ func main() {
 		}
 	}()
 
-	// 7. Periodically check GitHub for a newer build and apply it
+	// 8. Periodically check GitHub for a newer build and apply it
 	go updateLoop(exeDir)
 
+	// 9. Worker pool for media downloads, so bulk approvals don't saturate
+	// the link the playing ad is streaming over.
+	startDownloadWorkers()
+
+	// 10. Restart the kiosk if its renderer freezes while the process lives.
+	go fpsWatchdog()
+
 	// Block main goroutine forever
 	select {}
 }
 
 // ─── Dashboard server ─────────────────────────────────────────────────────────
 
-// spaHandler wraps a file server so any path that doesn't match a real file
-// falls back to index.html — required for React Router client-side routing.
+// spaHandler wraps a file server so any extension-less path that doesn't
+// match a real file falls back to index.html — required for React Router
+// client-side routing. Paths that look like asset requests (a file extension
+// other than .html) get a real 404 instead, so a missing bundle file or a
+// typo'd URL doesn't come back as a misleading 200 with an HTML body.
 func spaHandler(fsys fs.FS) http.Handler {
 	fileServer := http.FileServer(http.FS(fsys))
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -323,7 +777,17 @@ func spaHandler(fsys fs.FS) http.Handler {
 			fileServer.ServeHTTP(w, r)
 			return
 		}
-		// Unknown path → serve index.html so React Router handles it
+		if name == "favicon.ico" {
+			// Not in this bundle — an empty 204 keeps browser consoles quiet
+			// without handing back an HTML body.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if ext := path.Ext(name); ext != "" && ext != ".html" {
+			http.NotFound(w, r)
+			return
+		}
+		// Unknown route path → serve index.html so React Router handles it
 		http.ServeFileFS(w, r, fsys, "index.html")
 	})
 }
@@ -352,19 +816,50 @@ func serveDash() {
 		log.Fatalf("Dashboard: embed FS error: %v", err)
 	}
 
+	// With an unreadable store, serving the normal API would hand out empty
+	// playlists and accept writes that can never persist. Serve an explicit
+	// maintenance response instead so the breakage is visible, not silent.
+	if dbBroken.Load() {
+		log.Printf("Dashboard: store is broken — serving maintenance page only")
+		maint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				http.Error(w, `{"error":"database unavailable"}`, http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("<!doctype html><title>Maintenance</title><h1>Shell Ads is temporarily unavailable</h1><p>The ad database could not be loaded. Check ads.json on the kiosk machine or restore a backup.</p>"))
+		})
+		log.Printf("Dashboard: listening on %s (maintenance mode)", dashPort)
+		if err := listenAndServeMaybeTLS(dashPort, corsMiddleware(maint)); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Dashboard: %v", err)
+		}
+		return
+	}
+
 	mux := http.NewServeMux()
 
 	// ── Public API ────────────────────────────────────────────────────────────
-	mux.HandleFunc("POST /api/submit-ads", handleSubmitAds)
+	mux.HandleFunc("POST /api/submit-ads", withIdempotency(handleSubmitAds))
 	mux.HandleFunc("POST /api/activate", handleActivate)
 	mux.HandleFunc("GET /api/playlist", handlePlaylist)
+	mux.HandleFunc("GET /api/playlist/stream", handlePlaylistStream) // SSE: push on live-set change
 	mux.HandleFunc("GET /api/live-ads", handleLiveAds)
-	mux.HandleFunc("GET /api/kiosk/nav-poll", handleNavPoll)    // kiosk long-polls this
-	mux.HandleFunc("GET /api/submission-status", handleSubmissionStatus)  // public: poll ad status by IDs
-	mux.HandleFunc("GET /api/my-submissions", handleMySubmissions)         // public: all submissions for a submitter email
+	mux.HandleFunc("GET /api/kiosk/nav-poll", handleNavPoll)                     // kiosk long-polls this
+	mux.HandleFunc("POST /api/kiosk/heartbeat", handleKioskHeartbeat)            // kiosk liveness reports
+	mux.HandleFunc("POST /api/kiosk/report-error", handleKioskReportError)       // kiosk playback failures
+	mux.HandleFunc("POST /api/kiosk/screenshot", handleKioskScreenshot)          // kiosk pushes screen captures
+	mux.HandleFunc("GET /api/submission-status", handleSubmissionStatus)         // public: poll ad status by IDs
+	mux.HandleFunc("GET /api/my-submissions", handleMySubmissions)               // public: all submissions for a submitter email
 	mux.HandleFunc("DELETE /api/my-submissions/{id}", handleRetractMySubmission) // public: retract own submission
-	mux.HandleFunc("POST /api/upload-media", handleUploadMedia)             // public: upload media file as base64/text
-	mux.HandleFunc("GET /api/restart-warning", handleRestartWarning)        // public: upcoming scheduled kiosk restart info
+	mux.HandleFunc("POST /api/upload-media", handleUploadMedia)                  // public: upload media file as base64/text
+	mux.HandleFunc("GET /api/restart-warning", handleRestartWarning)             // public: upcoming scheduled kiosk restart info
+	mux.HandleFunc("GET /api/health", handleHealth)                              // public: true kiosk liveness for uptime monitors
+	mux.HandleFunc("GET /api/status", handleStatus)                              // public: same shape the old dash binary served
+	mux.HandleFunc("GET /api/config", handleConfig)                              // public: non-sensitive settings for the dash frontend
+	mux.HandleFunc("GET /api/display-config", handleDisplayConfig)               // public: the kiosk reads this at startup
+	mux.HandleFunc("GET /api/version", handleVersion)                            // public: fleet inventory
+	mux.HandleFunc("GET /api/idle-screen", handleIdleScreen)                     // public: what the kiosk shows when the playlist is empty
 
 	// ── Serve locally-cached media files ──────────────────────────────────────────
 	mux.HandleFunc("/media/", func(w http.ResponseWriter, r *http.Request) {
@@ -373,38 +868,80 @@ func serveDash() {
 		if strings.HasSuffix(r.URL.Path, ".html") {
 			w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		}
+		// Touch mtime so the LRU media cleanup sees this file as recently used.
+		if name := filepath.Base(r.URL.Path); name != "" && name != "/" {
+			now := time.Now()
+			_ = os.Chtimes(filepath.Join(mediaDir, name), now, now)
+		}
 		http.StripPrefix("/media/", http.FileServer(http.Dir(mediaDir))).ServeHTTP(w, r)
 	})
 
 	// ── Admin auth ────────────────────────────────────────────────────────────
 	mux.HandleFunc("POST /api/admin/auth", handleAdminAuth)
+	mux.HandleFunc("POST /api/admin/refresh", requireAdmin(handleAdminRefresh))
 
 	// ── Admin protected ───────────────────────────────────────────────────────
 	mux.HandleFunc("GET /api/admin/state", requireAdmin(handleAdminState))
 	mux.HandleFunc("GET /api/admin/stats", requireAdmin(handleAdminStats))
+	mux.HandleFunc("GET /api/admin/preview/{id}", requireAdmin(handleAdminPreview))
+	mux.HandleFunc("GET /api/admin/thumbnail/{id}", requireAdmin(handleAdminThumbnail))
+	mux.HandleFunc("GET /api/admin/kiosk-logs", requireAdmin(handleAdminKioskLogs))
+	mux.HandleFunc("GET /api/admin/kiosk-logs/stream", requireAdmin(handleAdminKioskLogStream))
+	mux.HandleFunc("GET /api/admin/launcher-logs", requireAdmin(handleAdminLauncherLogs))
 	mux.HandleFunc("PUT /api/admin/reorder", requireAdmin(handleAdminReorder))
 	mux.HandleFunc("DELETE /api/admin/active/{id}", requireAdmin(handleAdminDeleteActive))
 	mux.HandleFunc("DELETE /api/admin/submitted/{id}", requireAdmin(handleAdminDeleteSubmitted))
 	mux.HandleFunc("DELETE /api/admin/approved/{id}", requireAdmin(handleAdminDeleteApproved))
 	mux.HandleFunc("DELETE /api/admin/denied/{id}", requireAdmin(handleAdminDeleteDenied))
 	mux.HandleFunc("PATCH /api/admin/ads/{id}/duration", requireAdmin(handleAdminSetDuration))
+	mux.HandleFunc("PATCH /api/admin/ads/{id}", requireAdmin(handleAdminUpdateAd))
+	mux.HandleFunc("PUT /api/admin/ads/{id}/default", requireAdmin(handleAdminSetDefault))
+	mux.HandleFunc("PUT /api/admin/idle-screen", requireAdmin(handleAdminSetIdleScreen))
+	mux.HandleFunc("GET /api/admin/screenshot", requireAdmin(handleAdminScreenshot))
+	mux.HandleFunc("GET /api/admin/screenshot.jpg", requireAdmin(handleAdminScreenshotImage))
+	mux.HandleFunc("GET /api/admin/screenshot/info", requireAdmin(handleAdminScreenshotInfo))
+	mux.HandleFunc("POST /api/admin/request-screenshot", requireAdmin(handleAdminRequestScreenshot))
+	mux.HandleFunc("GET /api/admin/banned", requireAdmin(handleAdminListBanned))
+	mux.HandleFunc("PUT /api/admin/banned/{email}", requireAdmin(handleAdminBanSubmitter))
+	mux.HandleFunc("DELETE /api/admin/banned/{email}", requireAdmin(handleAdminUnbanSubmitter))
+	mux.HandleFunc("POST /api/admin/emergency", requireAdmin(handleAdminEmergency))
+	mux.HandleFunc("DELETE /api/admin/emergency", requireAdmin(handleAdminClearEmergency))
 	mux.HandleFunc("POST /api/admin/submitted/{id}/approve", requireAdmin(handleAdminApproveSubmitted))
 	mux.HandleFunc("POST /api/admin/approved/{id}/activate", requireAdmin(handleAdminActivateApproved))
 	mux.HandleFunc("POST /api/admin/active/{id}/deactivate", requireAdmin(handleAdminDeactivateActive))
 	mux.HandleFunc("POST /api/admin/clear", requireAdmin(handleAdminClearActive))
+	mux.HandleFunc("POST /api/admin/undo", requireAdmin(handleAdminUndo))
+	mux.HandleFunc("GET /api/admin/playlists", requireAdmin(handleAdminListPlaylists))
+	mux.HandleFunc("PUT /api/admin/playlists/{name}", requireAdmin(handleAdminSetNamedPlaylist))
+	mux.HandleFunc("DELETE /api/admin/playlists/{name}", requireAdmin(handleAdminDeleteNamedPlaylist))
+	mux.HandleFunc("GET /api/admin/playback-history", requireAdmin(handleAdminPlaybackHistory))
+	mux.HandleFunc("GET /api/admin/report.csv", requireAdmin(handleAdminPlaybackReport))
+	mux.HandleFunc("GET /api/admin/submissions-open", requireAdmin(handleAdminGetSubmissionsOpen))
+	mux.HandleFunc("PUT /api/admin/submissions-open", requireAdmin(handleAdminSetSubmissionsOpen))
+	mux.HandleFunc("GET /api/admin/schedule", requireAdmin(handleAdminGetSchedule))
+	mux.HandleFunc("PUT /api/admin/schedule", requireAdmin(handleAdminSetSchedule))
+	mux.HandleFunc("PUT /api/admin/display-config", requireAdmin(handleAdminSetDisplayRotation))
 	mux.HandleFunc("POST /api/admin/reload", requireAdmin(handleAdminReload))
 	mux.HandleFunc("POST /api/admin/restart-kiosk", requireAdmin(handleAdminRestartKiosk))
 	mux.HandleFunc("POST /api/admin/kiosk/next", requireAdmin(handleAdminKioskNext))
 	mux.HandleFunc("POST /api/admin/kiosk/prev", requireAdmin(handleAdminKioskPrev))
 	mux.HandleFunc("POST /api/admin/trigger-update", requireAdmin(handleAdminTriggerUpdate))
+	mux.HandleFunc("GET /api/admin/check-update", requireAdmin(handleAdminCheckUpdate))
 	mux.HandleFunc("GET /api/admin/update-status", requireAdmin(handleAdminUpdateStatus))
+	mux.HandleFunc("GET /api/admin/settings", requireAdmin(handleAdminGetSettings))
+	mux.HandleFunc("GET /api/admin/export", requireAdmin(handleAdminExport))
+	mux.HandleFunc("POST /api/admin/import", requireAdmin(handleAdminImport))
+	mux.HandleFunc("POST /api/admin/backup-now", requireAdmin(handleAdminBackupNow))
+	mux.HandleFunc("GET /api/admin/backups", requireAdmin(handleAdminListBackups))
+	mux.HandleFunc("PUT /api/admin/settings", requireAdmin(handleAdminPutSettings))
 	mux.HandleFunc("DELETE /api/admin/logout", requireAdmin(handleAdminLogout))
 
 	// ── SPA fallback ──────────────────────────────────────────────────────────
 	mux.Handle("/", spaHandler(sub))
 
 	log.Printf("Dashboard: http://localhost%s  |  Admin: http://localhost%s/admin", dashPort, dashPort)
-	if err := http.ListenAndServe(dashPort, corsMiddleware(mux)); err != nil {
+	// ErrServerClosed is the updater draining us before re-exec, not a failure.
+	if err := listenAndServeMaybeTLS(dashPort, corsMiddleware(mux)); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Dashboard server: %v", err)
 	}
 }
@@ -445,114 +982,297 @@ func downloadToMedia(adID, rawURL string) string {
 		return b.String()
 	}()
 
-	destPath := filepath.Join(mediaDir, safe+ext)
-
-	// If already cached, return immediately.
-	if _, err := os.Stat(destPath); err == nil {
-		log.Printf("Media: %s already cached, skipping download", safe+ext)
-		return "/media/" + safe + ext
+	log.Printf("Media: downloading %s", rawURL)
+	// Partial data survives in a stable .part file (named by ad, since the
+	// content hash isn't known yet) so a retry after a dropped connection
+	// resumes instead of restarting a large video from zero — field links
+	// are flaky enough that this matters. The validator sidecar remembers
+	// the ETag/Last-Modified the partial came from: resuming against a file
+	// that changed remotely would splice old and new bytes into a corrupt
+	// blob, so without a validator to send as If-Range the partial is
+	// worthless and gets discarded.
+	partPath := filepath.Join(mediaDir, safe+ext+".part")
+	validatorPath := partPath + ".validator"
+	var offset int64
+	if fi, err := os.Stat(partPath); err == nil {
+		offset = fi.Size()
+	}
+	var validator string
+	if offset > 0 {
+		if b, err := os.ReadFile(validatorPath); err == nil {
+			validator = strings.TrimSpace(string(b))
+		}
+		if validator == "" {
+			log.Printf("Media: no validator for partial %s — restarting from zero", safe+ext)
+			os.Remove(partPath)
+			offset = 0
+		}
 	}
 
-	log.Printf("Media: downloading %s → %s", rawURL, safe+ext)
 	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
 	if err != nil {
 		log.Printf("Media: build request failed: %v", err)
 		return rawURL
 	}
-	resp, err := httpClient.Do(req)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// If-Range makes the resume conditional: a changed file comes back
+		// as a full 200 instead of a mismatched 206.
+		req.Header.Set("If-Range", validator)
+	}
+	resp, err := mediaClient.Do(req)
 	if err != nil {
 		log.Printf("Media: download failed: %v", err)
 		return rawURL
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
+
+	// total is the expected final size, when the server tells us (-1 otherwise).
+	total := int64(-1)
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		log.Printf("Media: resuming %s at byte %d", safe+ext, offset)
+		flags |= os.O_APPEND
+		// Content-Range: bytes <start>-<end>/<total>
+		if parts := strings.SplitN(resp.Header.Get("Content-Range"), "/", 2); len(parts) == 2 {
+			if n, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+				total = n
+			}
+		}
+	case http.StatusOK:
+		// A fresh download, a server that ignored the Range header, or an
+		// If-Range miss because the remote file changed — start over
+		// cleanly either way.
+		if offset > 0 {
+			log.Printf("Media: full response despite Range — restarting %s", safe+ext)
+		}
+		offset = 0
+		flags |= os.O_TRUNC
+		if resp.ContentLength > 0 {
+			total = resp.ContentLength
+		}
+		// Remember what this partial will belong to, for the next resume.
+		newValidator := resp.Header.Get("ETag")
+		if newValidator == "" {
+			newValidator = resp.Header.Get("Last-Modified")
+		}
+		if newValidator != "" {
+			_ = os.WriteFile(validatorPath, []byte(newValidator), 0o644)
+		} else {
+			os.Remove(validatorPath)
+		}
+	default:
 		log.Printf("Media: server returned %s for %s", resp.Status, rawURL)
 		return rawURL
 	}
 
-	// Write to temp file then atomically rename.
-	tmp, err := os.CreateTemp(mediaDir, "dl-*")
+	f, err := os.OpenFile(partPath, flags, 0o644)
 	if err != nil {
-		log.Printf("Media: create temp: %v", err)
+		log.Printf("Media: open part file: %v", err)
+		return rawURL
+	}
+	_, copyErr := io.Copy(f, resp.Body)
+	closeErr := f.Close()
+	if copyErr != nil || closeErr != nil {
+		// Keep the .part file — that's the whole point of resuming.
+		log.Printf("Media: write interrupted for %s (will resume): copy=%v close=%v", safe+ext, copyErr, closeErr)
+		return rawURL
+	}
+
+	if fi, err := os.Stat(partPath); err != nil {
+		log.Printf("Media: stat part file: %v", err)
+		return rawURL
+	} else if total >= 0 && fi.Size() != total {
+		if fi.Size() > total {
+			// More bytes than the server promised — the .part can't be
+			// trusted as a resume base either.
+			os.Remove(partPath)
+			os.Remove(validatorPath)
+		}
+		log.Printf("Media: %s incomplete (%d of %d bytes) — not cached", safe+ext, fi.Size(), total)
 		return rawURL
 	}
-	tmpName := tmp.Name()
-	_, copyErr := io.Copy(tmp, resp.Body)
-	tmp.Close()
-	if copyErr != nil {
-		os.Remove(tmpName)
-		log.Printf("Media: write failed: %v", copyErr)
+
+	// Final name is the content hash, so identical media downloaded for
+	// different ads lands on one file and deletion can refcount it.
+	name, err := contentHashName(partPath, ext)
+	if err != nil {
+		log.Printf("Media: hash failed: %v", err)
 		return rawURL
 	}
-	if err := os.Rename(tmpName, destPath); err != nil {
-		os.Remove(tmpName)
+	destPath := filepath.Join(mediaDir, name)
+	if _, err := os.Stat(destPath); err == nil {
+		os.Remove(partPath)
+		os.Remove(validatorPath)
+		log.Printf("Media: %s already cached as %s — reusing", rawURL, name)
+		return "/media/" + name
+	}
+	if err := os.Rename(partPath, destPath); err != nil {
+		os.Remove(partPath)
 		log.Printf("Media: rename failed: %v", err)
 		return rawURL
 	}
-	log.Printf("Media: cached %s", safe+ext)
-	return "/media/" + safe + ext
+	os.Remove(validatorPath)
+	log.Printf("Media: cached %s as %s", rawURL, name)
+	return "/media/" + name
 }
 
 // handleSubmitAds queues incoming ads as "submitted" — not visible to the kiosk
 // until an admin approves them AND either the Z key is pressed or reload is called.
 func handleSubmitAds(w http.ResponseWriter, r *http.Request) {
+	if !dbSubmissionsOpen() {
+		http.Error(w, `{"error":"submissions are closed right now — check back later"}`, http.StatusServiceUnavailable)
+		return
+	}
+	// Direct uploads (and HTML ads with bundled assets) arrive as
+	// multipart; plain JSON batches keep the original URL-based path.
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		handleSubmitMultipart(w, r)
+		return
+	}
 	var incoming []dashAd
 	if err := json.NewDecoder(r.Body).Decode(&incoming); err != nil {
 		http.Error(w, "bad JSON", http.StatusBadRequest)
 		return
 	}
 
+	// Per-ad outcome, so a partly-invalid batch tells the submitter exactly
+	// which ads went through and why the rest didn't.
+	type submitResult struct {
+		ID       string `json:"id"`
+		Accepted bool   `json:"accepted"`
+		Reason   string `json:"reason,omitempty"`
+	}
+	results := make([]submitResult, 0, len(incoming))
+	reject := func(id, reason string) {
+		log.Printf("Submit: rejected ad %q — %s", id, reason)
+		results = append(results, submitResult{ID: id, Accepted: false, Reason: reason})
+	}
+
+	quota := dbMaxAdsPerUser()
+	accepted := 0
 	for _, d := range incoming {
+		_, email := splitSubmitter(d.SubmittedBy)
+		if dbIsBanned(email) {
+			reject(d.ID, "submitter is banned")
+			continue
+		}
+		if quota > 0 {
+			// Recounted per ad so a batch can't blow past the cap mid-request.
+			if used := dbPipelineCount(email); used >= quota {
+				reject(d.ID, fmt.Sprintf("you have %d/%d pending ads; remove one first", used, quota))
+				continue
+			}
+		}
+		durMs := d.DurationSec * 1000
+		if durMs <= 0 {
+			durMs = dbDefaultDurationMs()
+		}
+		adType, src := d.Type, d.URL
+		// "auto" only makes sense for video — play once through at the
+		// media's real length. Everything else gets a fixed duration,
+		// clamped to the same bounds the admin duration endpoint enforces
+		// (a 5-minute static image is almost certainly a typo).
+		durationMode := ""
+		if d.DurationMode == "auto" && adType == "video" {
+			durationMode = "auto"
+		}
+		if adType != "video" {
+			const minMs, maxMs = 1000, 30000
+			if durMs < minMs {
+				durMs = minMs
+			}
+			if durMs > maxMs {
+				durMs = maxMs
+			}
+		}
+		if d.Type == "embed" {
+			// A watch-page link, not a media file: validate + normalize it,
+			// then synthesize an iframe page the kiosk renders as HTML.
+			embedURL, err := normalizeEmbedURL(d.URL)
+			if err != nil {
+				reject(d.ID, err.Error())
+				continue
+			}
+			fileName := safeFileName(d.ID) + "-embed.html"
+			if err := os.WriteFile(filepath.Join(mediaDir, fileName), []byte(embedAdHTML(embedURL)), 0o644); err != nil {
+				log.Printf("Submit: embed ad %q: write html failed: %v", d.ID, err)
+				reject(d.ID, "could not store embed page")
+				continue
+			}
+			adType, src = "html", "/media/"+fileName
+		}
 		ad := kioskAd{
-			ID:          d.ID,
-			Name:        d.Name,
-			Type:        d.Type,
-			DurationMs:  d.DurationSec * 1000,
-			Src:         d.URL,
-			Transition:  adTransition{Enter: "fade", Exit: "fade"},
-			SubmittedBy: d.SubmittedBy,
+			ID:           d.ID,
+			Name:         d.Name,
+			Type:         adType,
+			DurationMs:   durMs,
+			DurationMode: durationMode,
+			Src:          src,
+			Transition:   adTransition{Enter: normalizeTransition(d.Enter), Exit: normalizeTransition(d.Exit)},
+			SubmittedBy:  d.SubmittedBy,
 		}
 		if err := dbSaveAd(ad, d.URL); err != nil {
 			log.Printf("Submit: failed to save ad %q: %v", d.ID, err)
+			reject(d.ID, "could not be saved")
 			continue
 		}
-		// Download remote file to /media/ in the background so the kiosk always
+		results = append(results, submitResult{ID: d.ID, Accepted: true})
+		accepted++
+		// Queue the remote file for caching under /media/ so the kiosk always
 		// plays from local storage and file.io one-time links don’t expire.
-		if d.URL != "" && !strings.HasPrefix(d.URL, "/media/") {
-			go func(id, src string) {
-				newSrc := downloadToMedia(id, src)
-				if newSrc != src {
-					dbUpdateSrc(id, newSrc)
-					log.Printf("Submit: ad %q media cached as %s", id, newSrc)
-				}
-			}(d.ID, d.URL)
+		// (Embed ads are already local — src points at the synthesized page.)
+		if src != "" && !strings.HasPrefix(src, "/media/") {
+			queueMediaDownload(d.ID, src)
 		}
 	}
 
-	log.Printf("Submit: %d ad(s) queued for admin review", len(incoming))
+	log.Printf("Submit: %d of %d ad(s) queued for admin review", accepted, len(incoming))
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	// Fully-rejected batches keep their HTTP-level signal (403 for bans,
+	// 429 for quota) so simple clients still notice; the body carries the
+	// per-ad detail either way.
+	if accepted == 0 && len(incoming) > 0 {
+		code := http.StatusForbidden
+		for _, res := range results {
+			if strings.Contains(res.Reason, "pending ads") {
+				code = http.StatusTooManyRequests
+				break
+			}
+		}
+		w.WriteHeader(code)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok":       accepted > 0 || len(incoming) == 0,
+		"accepted": accepted,
+		"results":  results,
+	})
 }
 
 // handleActivate is called by the kiosk Z-key.
 // It moves ONLY admin-approved ads into the live playlist.
 func handleActivate(w http.ResponseWriter, r *http.Request) {
-	activated := dbMoveApprovedToLive()
+	activated, skipped := dbMoveApprovedToLive()
+	if activated == 0 && skipped > 0 {
+		msg := fmt.Sprintf(`{"error":"live playlist is at its cap of %d ads"}`, dbMaxLiveAds())
+		http.Error(w, msg, http.StatusConflict)
+		return
+	}
+	if skipped > 0 {
+		log.Printf("Activate (Z-key): %d ad(s) left in approved: live cap is %d", skipped, dbMaxLiveAds())
+	}
 	log.Printf("Activate (Z-key): %d approved ad(s) → live", activated)
 	if activated > 0 {
 		signalPlaylistChanged()
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "activated": activated})
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "activated": activated, "skipped": skipped})
 }
 
-// handlePlaylist serves the current active (live) playlist as JSON.
-// The kiosk’s PLAYLIST_URL points at this endpoint.
-func handlePlaylist(w http.ResponseWriter, r *http.Request) {
-	ads := dbLiveOrdered()
-
-	// Resolve /media/ relative paths to absolute localhost URLs so the kiosk
-	// HTTP client can download them.
+// resolveMediaURLs rewrites /media/ relative paths to absolute localhost URLs
+// so the kiosk HTTP client can download them.
+func resolveMediaURLs(ads []kioskAd) []kioskAd {
 	resolved := make([]kioskAd, len(ads))
 	for i, ad := range ads {
 		if strings.HasPrefix(ad.Src, "/media/") {
@@ -560,8 +1280,94 @@ func handlePlaylist(w http.ResponseWriter, r *http.Request) {
 		}
 		resolved[i] = ad
 	}
+	return resolved
+}
+
+// handlePlaylist serves the current active (live) playlist as JSON.
+// The kiosk’s PLAYLIST_URL points at this endpoint. An optional ?name=
+// query selects a named playlist instead, so one launcher can drive several
+// screens each configured with a different PLAYLIST_URL suffix.
+func handlePlaylist(w http.ResponseWriter, r *http.Request) {
+	touchKioskContact()
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	shuffle := r.URL.Query().Get("shuffle") == "true"
+
+	// Day-parting: with no explicit ?name, the schedule can pick one by time
+	// of day. The chosen name goes into the ETag so the crossover between
+	// two day-parts invalidates the kiosk's cached copy even though no
+	// mutation bumped the version.
+	scheduled := ""
+	if name == "" {
+		if pl, ok := dbScheduledPlaylist(time.Now()); ok {
+			scheduled = pl
+		}
+	}
+
+	// Every mutation (including emergency set/clear) bumps playlistVersion,
+	// so a version-based ETag is valid for all branches below. The launcher
+	// start time is mixed in so a restart never resurrects a stale match.
+	etag := fmt.Sprintf("\"pl-%s-%s-%d-%d\"", name, scheduled, launcherStartedAt.Unix(), playlistVersion.Load())
+	if shuffle {
+		etag = strings.Replace(etag, "\"pl-", "\"pl-sh-", 1)
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+
+	// An emergency broadcast preempts everything, named playlists included.
+	if em, ok := dbEmergencyAd(); ok {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resolveMediaURLs([]kioskAd{em}))
+		return
+	}
+	var ads []kioskAd
+	if name != "" {
+		named, ok := dbPlaylistAds(name)
+		if !ok {
+			http.Error(w, `{"error":"playlist not found"}`, http.StatusNotFound)
+			return
+		}
+		ads = named
+	} else {
+		if scheduled != "" {
+			// A missing scheduled playlist (deleted after the schedule was
+			// written) falls through to the live set rather than blanking
+			// the screen.
+			if named, ok := dbPlaylistAds(scheduled); ok {
+				ads = named
+			}
+		}
+		if ads == nil {
+			ads = dbLiveOrdered()
+		}
+		if len(ads) == 0 {
+			// Nothing live (fresh install, or an admin just cleared the
+			// playlist) — fall back to the default set so the screen is
+			// never blank.
+			ads = dbDefaultAds()
+		}
+	}
+
+	if shuffle {
+		shuffleAds(ads)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resolved)
+	_ = json.NewEncoder(w).Encode(resolveMediaURLs(ads))
+}
+
+// shuffleAds randomizes playback order for screens that ask for it
+// (?shuffle=true). The permutation is seeded from the same inputs as the
+// playlist ETag, so the order is stable across polls — the kiosk never jumps
+// around mid-cycle — and reshuffles exactly when the live set changes.
+func shuffleAds(ads []kioskAd) {
+	seed := launcherStartedAt.Unix() ^ playlistVersion.Load()
+	rng := mrand.New(mrand.NewSource(seed))
+	rng.Shuffle(len(ads), func(i, j int) {
+		ads[i], ads[j] = ads[j], ads[i]
+	})
 }
 
 // handleLiveAds is a browser-friendly public endpoint that returns
@@ -582,47 +1388,192 @@ func handleAdminAuth(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "bad JSON", http.StatusBadRequest)
 		return
 	}
-	log.Printf("Admin: attempting login with password=[%s], expected=[%s], equal=%v", body.Password, adminPassword, body.Password == adminPassword)
-	if body.Password != adminPassword {
+	ip := clientIP(r)
+	if authRateLimited(ip) {
+		log.Printf("Admin: too many login attempts from %s — throttled", ip)
+		w.Header().Set("Retry-After", strconv.Itoa(int(authWindow.Seconds())))
+		http.Error(w, `{"error":"too many attempts, slow down"}`, http.StatusTooManyRequests)
+		return
+	}
+	// Constant-time comparison so response timing doesn't leak how much of
+	// the password matched.
+	if subtle.ConstantTimeCompare([]byte(body.Password), []byte(adminPassword)) != 1 {
+		log.Printf("Admin: failed login from %s", ip)
 		http.Error(w, `{"error":"wrong password"}`, http.StatusUnauthorized)
 		return
 	}
 	token := generateToken()
-	adminTokens.Store(token, tokenEntry{expiry: time.Now().Add(24 * time.Hour)})
+	dbAddSession(tokenHash(token), time.Now().Add(adminTokenTTL))
 	log.Printf("Admin: login")
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+	_ = json.NewEncoder(w).Encode(map[string]any{"token": token, "expiresInSec": int(adminTokenTTL.Seconds())})
+}
+
+// handleAdminRefresh rotates a still-valid token: a new token with a fresh
+// expiry is issued and the presented one is invalidated, so a dashboard left
+// open across the 24 h window can extend its session instead of silently
+// starting to 401 mid-review.
+func handleAdminRefresh(w http.ResponseWriter, r *http.Request) {
+	old := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	token := generateToken()
+	dbAddSession(tokenHash(token), time.Now().Add(adminTokenTTL))
+	dbDeleteSession(tokenHash(old))
+	log.Printf("Admin: token refreshed")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"token": token, "expiresInSec": int(adminTokenTTL.Seconds())})
 }
 
 func handleAdminLogout(w http.ResponseWriter, r *http.Request) {
 	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
-	adminTokens.Delete(token)
+	dbDeleteSession(tokenHash(token))
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 }
 
-func handleAdminState(w http.ResponseWriter, r *http.Request) {
+// ─── Idle screen ──────────────────────────────────────────────────────────────
+//
+// What the kiosk shows when the playlist comes back empty: a short message
+// and background colour, or a full HTML snippet (sanitized at set time).
+// Stored in settings so it survives restarts and rides along in backups.
+
+const (
+	settingIdleMessage    = "idle_message"
+	settingIdleBackground = "idle_background"
+	settingIdleHTML       = "idle_html"
+)
+
+// handleIdleScreen is the kiosk's fallback when the playlist is empty —
+// branding or a "coming soon" card instead of a black customer-facing screen.
+func handleIdleScreen(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"active":    dbLiveOrdered(),
-		"approved":  dbByStatus(adStatusApproved),
-		"submitted": dbByStatus(adStatusSubmitted),
-		"denied":    dbByStatus(adStatusDenied),
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message":    dbGetSetting(settingIdleMessage, ""),
+		"background": dbGetSetting(settingIdleBackground, "#000000"),
+		"html":       dbGetSetting(settingIdleHTML, ""),
 	})
 }
 
-func handleAdminStats(w http.ResponseWriter, r *http.Request) {
-	kioskMu.Lock()
-	pid := kioskPID
-	startedAt := kioskStartedAt
-	restarts := kioskRestarts
-	running := activeKiosk != nil && activeKiosk.Process != nil
-	kioskMu.Unlock()
-
-	counts := dbCounts()
-
-	var uptimeSec float64
-	if running && !startedAt.IsZero() {
+// handleAdminSetIdleScreen updates the idle screen config.
+// Body: { "message"?: "...", "background"?: "#rrggbb", "html"?: "..." }
+// Omitted fields are left unchanged; HTML goes through the same sanitizer as
+// emergency broadcasts.
+func handleAdminSetIdleScreen(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Message    *string `json:"message"`
+		Background *string `json:"background"`
+		HTML       *string `json:"html"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	if body.Message != nil {
+		dbSetSetting(settingIdleMessage, strings.TrimSpace(*body.Message))
+	}
+	if body.Background != nil {
+		dbSetSetting(settingIdleBackground, strings.TrimSpace(*body.Background))
+	}
+	if body.HTML != nil {
+		dbSetSetting(settingIdleHTML, sanitizeHTML(*body.HTML))
+	}
+	log.Printf("Admin: idle screen updated")
+	handleIdleScreen(w, r)
+}
+
+// handleAdminListBanned returns the submitter deny-list for review.
+func handleAdminListBanned(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dbListBanned())
+}
+
+// handleAdminBanSubmitter adds an email to the deny-list and sweeps any of
+// their ads still sitting in the submitted queue into denied.
+// Body (optional): { "reason": "..." }
+func handleAdminBanSubmitter(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.PathValue("email"))
+	if email == "" {
+		http.Error(w, `{"error":"email required"}`, http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body) // empty body is fine
+	swept := dbBanSubmitter(email, strings.TrimSpace(body.Reason))
+	log.Printf("Admin: banned submitter %s (%d pending ad(s) denied)", email, swept)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "sweptPending": swept})
+}
+
+func handleAdminUnbanSubmitter(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.PathValue("email"))
+	if !dbUnbanSubmitter(email) {
+		http.Error(w, `{"error":"not banned"}`, http.StatusNotFound)
+		return
+	}
+	log.Printf("Admin: lifted ban on submitter %s", email)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+func handleAdminState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"active":    dbLiveOrdered(),
+		"approved":  dbByStatus(adStatusApproved),
+		"submitted": dbByStatus(adStatusSubmitted),
+		"denied":    dbByStatus(adStatusDenied),
+	})
+}
+
+func handleAdminStats(w http.ResponseWriter, r *http.Request) {
+	kioskMu.Lock()
+	pid := kioskPID
+	startedAt := kioskStartedAt
+	restarts := kioskRestarts
+	running := activeKiosk != nil && activeKiosk.Process != nil
+	kioskMu.Unlock()
+
+	counts := dbCounts()
+	defaults := len(dbDefaultAds())
+	_, emergencyActive := dbEmergencyAd()
+
+	heartbeatMu.RLock()
+	kioskBuild := lastHeartbeat.BuildNumber
+	currentAdID := lastHeartbeat.CurrentAdID
+	screenW, screenH := lastHeartbeat.ScreenWidth, lastHeartbeat.ScreenHeight
+	heartbeatFresh := !lastHeartbeatAt.IsZero() && time.Since(lastHeartbeatAt) <= heartbeatStaleAfter
+	heartbeatMu.RUnlock()
+
+	// nil until the kiosk has ever reported its screen size; resolution
+	// doesn't go stale the way the current ad does, so the last report
+	// stands even between heartbeats.
+	var kioskResolution map[string]any
+	if screenW > 0 && screenH > 0 {
+		kioskResolution = map[string]any{"width": screenW, "height": screenH}
+	}
+
+	// What's on screen right now, resolved across every stage — during a
+	// transition or an emergency broadcast the showing ad isn't in the live
+	// set, and the dashboard shouldn't go blank (or worse, stale) for it.
+	var currentAd map[string]any
+	if heartbeatFresh {
+		switch {
+		case currentAdID == "":
+			currentAd = map[string]any{"idle": true}
+		default:
+			currentAd = map[string]any{"id": currentAdID}
+			if ad, stage, found := dbFindAdAnyStage(currentAdID); found {
+				currentAd["name"] = ad.Name
+				currentAd["stage"] = stage
+			} else {
+				currentAd["stage"] = "unknown"
+			}
+		}
+	}
+
+	var uptimeSec float64
+	if running && !startedAt.IsZero() {
 		uptimeSec = time.Since(startedAt).Seconds()
 	}
 
@@ -638,27 +1589,308 @@ func handleAdminStats(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	nextUpdateCheckMu.RLock()
+	nextCheck := nextUpdateCheckAt
+	nextUpdateCheckMu.RUnlock()
+	var nextUpdateCheckSec float64
+	if !nextCheck.IsZero() {
+		if d := time.Until(nextCheck); d > 0 {
+			nextUpdateCheckSec = d.Seconds()
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]any{
 		"kiosk": map[string]any{
-			"running":   running,
-			"pid":       pid,
-			"uptimeSec": uptimeSec,
-			"restarts":  restarts,
+			"running":    running,
+			"pid":        pid,
+			"uptimeSec":  uptimeSec,
+			"uptime":     formatUptime(time.Duration(uptimeSec) * time.Second),
+			"restarts":   restarts,
+			"responsive": kioskResponsive(),
+			// Reported by the kiosk's heartbeat; a value different from the
+			// launcher build after an update means kiosk.exe never swapped.
+			"build": kioskBuild,
+			// nil when the heartbeat is stale — better to admit we don't
+			// know than to report the last thing the kiosk ever said.
+			"currentAd":  currentAd,
+			"resolution": kioskResolution,
 		},
 		"playlist": map[string]any{
 			"active":    counts[adStatusLive],
 			"approved":  counts[adStatusApproved],
 			"submitted": counts[adStatusSubmitted],
 			"denied":    counts[adStatusDenied],
+			"defaults":  defaults,
+			// True when the kiosk is showing the fallback set, so operators
+			// know the screen content is not the live playlist.
+			"servingDefaults": counts[adStatusLive] == 0 && defaults > 0,
 		},
 		"build":              BuildNumber,
+		"previousBuild":      dbGetSetting(settingPreviousBuild, ""),
+		"lastUpdateAt":       dbGetSetting(settingLastUpdateAt, ""),
+		"emergencyActive":    emergencyActive,
+		"submissionsOpen":    dbSubmissionsOpen(),
 		"updating":           updating.Load(),
+		"undoAvailable":      undoAvailable(),
 		"launcherUptimeSec":  launcherUptimeSec,
+		"launcherUptime":     formatUptime(time.Since(launcherStartedAt)),
+		"launcherStartedAt":  launcherStartedAt.UTC().Format(time.RFC3339),
 		"nextAutoRestartSec": nextAutoRestartSec,
+		"nextUpdateCheckSec": nextUpdateCheckSec,
+		// Media downloads queued or in flight behind the worker pool.
+		"downloadQueueDepth": downloadQueueDepth.Load(),
+	})
+}
+
+// handleStatus mirrors the /api/status the standalone dash binary used to
+// serve on this same port, so the shellnews tunnel and any other existing
+// consumers keep working with the dash retired. Same JSON shape; CORS comes
+// from the global middleware.
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	uptime := time.Since(launcherStartedAt)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status":         "online",
+		"uptime_seconds": int(uptime.Seconds()),
+		"uptime":         formatUptime(uptime),
+		"started_at":     launcherStartedAt.Format(time.RFC3339),
+		"server_time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleConfig returns the non-sensitive settings the public dashboard needs
+// before anyone authenticates: whether submissions are open (so the submit
+// button can hide proactively), the duration bounds the server will enforce
+// anyway, and the ad types it accepts. Nothing here is secret — it's the
+// contract the submit form should build itself from instead of hard-coding.
+func handleConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"submissionsOpen":    dbSubmissionsOpen(),
+		"minDurationSec":     1,
+		"maxDurationSec":     30,
+		"defaultDurationSec": dbDefaultDurationMs() / 1000,
+		"allowedAdTypes":     []string{"image", "video", "html", "embed"},
+		"maxAdsPerUser":      dbMaxAdsPerUser(),
+	})
+}
+
+// handleVersion answers fleet-inventory polls: which builds this box runs
+// and since when. Unauthenticated on purpose — build numbers aren't secrets,
+// and requiring a token would make external inventory scripts impractical.
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	heartbeatMu.RLock()
+	kioskBuild := lastHeartbeat.BuildNumber
+	heartbeatMu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"launcherBuild": BuildNumber,
+		"kioskBuild":    kioskBuild,
+		"startedAt":     launcherStartedAt.UTC().Format(time.RFC3339),
+		"os":            runtime.GOOS,
+		"arch":          runtime.GOARCH,
 	})
 }
 
+// formatUptime renders a duration the way the dash status page does
+// ("3h 12m 9s"), so both binaries report uptime in the same shape.
+func formatUptime(d time.Duration) string {
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh %dm %ds", h, m, s)
+	}
+	if m > 0 {
+		return fmt.Sprintf("%dm %ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
+}
+
+// kioskFreshnessWindow is how recently the kiosk must have polled for
+// /api/health to consider it alive.
+const kioskFreshnessWindow = 30 * time.Second
+
+// handleHealth returns 200 only when the kiosk process is running AND its
+// frontend has polled the launcher within the freshness window; otherwise 503
+// with a body explaining which check failed. This gives external uptime
+// monitors a real signal instead of "the HTTP server is up."
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	kioskMu.Lock()
+	running := activeKiosk != nil && activeKiosk.Process != nil
+	kioskMu.Unlock()
+
+	lastNano := kioskLastContact.Load()
+	var sinceContact float64
+	fresh := false
+	if lastNano != 0 {
+		since := time.Since(time.Unix(0, lastNano))
+		sinceContact = since.Seconds()
+		fresh = since <= kioskFreshnessWindow
+	}
+
+	healthy := running && fresh
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"healthy":            healthy,
+		"processRunning":     running,
+		"kioskPolling":       fresh,
+		"secSinceContact":    sinceContact,
+		"freshnessWindowSec": kioskFreshnessWindow.Seconds(),
+	})
+}
+
+// ─── Kiosk heartbeat ──────────────────────────────────────────────────────────
+//
+// The kiosk reports in every few seconds; if the process is running but no
+// heartbeat has arrived recently, the web view has almost certainly hung —
+// a failure mode process state alone can't see.
+
+// heartbeatStaleAfter is how long without a heartbeat before the kiosk is
+// considered unresponsive.
+const heartbeatStaleAfter = 15 * time.Second
+
+type kioskHeartbeat struct {
+	BuildNumber string  `json:"buildNumber"`
+	CurrentAdID string  `json:"currentAdId"`
+	FPS         float64 `json:"fps,omitempty"`
+	// Screen dimensions in physical pixels, so the dashboard can warn about
+	// badly mismatched ad aspect ratios. Zero when the kiosk build predates
+	// resolution reporting or the web view couldn't measure yet.
+	ScreenWidth  int `json:"screenWidth,omitempty"`
+	ScreenHeight int `json:"screenHeight,omitempty"`
+}
+
+var (
+	heartbeatMu     sync.RWMutex
+	lastHeartbeat   kioskHeartbeat
+	lastHeartbeatAt time.Time
+)
+
+// handleKioskHeartbeat records the kiosk's periodic liveness report.
+func handleKioskHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var hb kioskHeartbeat
+	if err := json.NewDecoder(r.Body).Decode(&hb); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	touchKioskContact()
+	heartbeatMu.Lock()
+	lastHeartbeat = hb
+	lastHeartbeatAt = time.Now()
+	heartbeatMu.Unlock()
+	if hb.FPS > 0 {
+		fpsSeen.Store(true)
+	}
+	recordPlayback(hb.CurrentAdID)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+// fpsSeen flips once the kiosk has ever reported a positive FPS, arming the
+// frozen-renderer watchdog. Builds that predate FPS reporting always send 0,
+// and restarting them for it would be a false positive loop.
+var fpsSeen atomic.Bool
+
+// fpsWatchdog restarts the kiosk when the renderer is provably stuck: the
+// process is running and heartbeating (so process monitoring sees nothing
+// wrong) but has reported FPS at or below the threshold for the whole stall
+// window. monitorKiosk brings it back up after stopKiosk.
+func fpsWatchdog() {
+	var stalledSince time.Time
+	for {
+		time.Sleep(5 * time.Second)
+		if updating.Load() {
+			stalledSince = time.Time{}
+			continue
+		}
+		kioskMu.Lock()
+		running := activeKiosk != nil && activeKiosk.Process != nil
+		kioskMu.Unlock()
+		heartbeatMu.RLock()
+		fps := lastHeartbeat.FPS
+		at := lastHeartbeatAt
+		heartbeatMu.RUnlock()
+		fresh := !at.IsZero() && time.Since(at) <= heartbeatStaleAfter
+		if !running || !fresh || !fpsSeen.Load() || fps > fpsStallThreshold {
+			stalledSince = time.Time{}
+			continue
+		}
+		if stalledSince.IsZero() {
+			stalledSince = time.Now()
+			continue
+		}
+		if time.Since(stalledSince) >= fpsStallWindow {
+			log.Printf("Watchdog: frozen renderer recovery — FPS stuck at %.1f for %s, restarting kiosk",
+				fps, time.Since(stalledSince).Round(time.Second))
+			stopKiosk()
+			stalledSince = time.Time{}
+		}
+	}
+}
+
+// handleKioskReportError records a playback failure the kiosk hit while
+// rendering an ad (dead URL, decode error, …) so the admin view can flag the
+// ad instead of it silently vanishing from rotation.
+// Body: { "adId": "...", "error": "..." }
+func handleKioskReportError(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		AdID  string `json:"adId"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.AdID) == "" {
+		http.Error(w, `{"error":"adId required"}`, http.StatusBadRequest)
+		return
+	}
+	touchKioskContact()
+	count, demoted, found := dbRecordPlaybackError(body.AdID, strings.TrimSpace(body.Error))
+	if !found {
+		http.Error(w, `{"error":"ad not found"}`, http.StatusNotFound)
+		return
+	}
+	log.Printf("Kiosk: playback error #%d for ad %q: %s", count, body.AdID, body.Error)
+	if demoted {
+		log.Printf("Kiosk: ad %q demoted to approved (broken) after %d playback errors", body.AdID, count)
+		signalPlaylistChanged()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "errorCount": count, "demoted": demoted})
+}
+
+// kioskResponsive reports whether a heartbeat arrived recently.
+func kioskResponsive() bool {
+	heartbeatMu.RLock()
+	defer heartbeatMu.RUnlock()
+	return !lastHeartbeatAt.IsZero() && time.Since(lastHeartbeatAt) <= heartbeatStaleAfter
+}
+
+// heartbeatWatch logs a warning (once per incident) when the kiosk process is
+// running but heartbeats have stopped.
+func heartbeatWatch() {
+	warned := false
+	for range time.Tick(5 * time.Second) {
+		kioskMu.Lock()
+		running := activeKiosk != nil && activeKiosk.Process != nil
+		kioskMu.Unlock()
+		if !running || kioskResponsive() {
+			warned = false
+			continue
+		}
+		if !warned {
+			log.Printf("Heartbeat: kiosk process running but no heartbeat for >%s — web view may have hung", heartbeatStaleAfter)
+			warned = true
+		}
+	}
+}
+
 // handleRestartWarning is a public (no-auth) endpoint the submit page polls
 // to show a heads-up banner before the scheduled kiosk auto-restart.
 func handleRestartWarning(w http.ResponseWriter, r *http.Request) {
@@ -702,7 +1934,7 @@ func handleAdminReorder(w http.ResponseWriter, r *http.Request) {
 
 func handleAdminDeleteActive(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
-	src, found := dbDelete(id)
+	src, found := dbRemove(id)
 	if !found {
 		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 		return
@@ -716,19 +1948,29 @@ func handleAdminDeleteActive(w http.ResponseWriter, r *http.Request) {
 
 func handleAdminDeleteSubmitted(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
+	// Optional note for the submitter; an empty or absent body denies
+	// without one.
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
 	// Move to denied (keep record for submitter status polling).
-	if !dbSetStatus(id, adStatusDenied) {
+	if !dbDenyAd(id, body.Reason) {
 		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 		return
 	}
-	log.Printf("Admin: denied submitted ad %q", id)
+	if body.Reason != "" {
+		log.Printf("Admin: denied submitted ad %q (%s)", id, strings.TrimSpace(body.Reason))
+	} else {
+		log.Printf("Admin: denied submitted ad %q", id)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 }
 
 func handleAdminDeleteApproved(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
-	src, found := dbDelete(id)
+	src, found := dbRemove(id)
 	if !found {
 		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 		return
@@ -785,6 +2027,201 @@ func handleAdminSetDuration(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 }
 
+// handleAdminEmergency installs a single broadcast ad (fire drill, closure
+// notice) that preempts every playlist until cleared. HTML content is
+// sanitized and cached under /media/ like uploaded ads; image ads reference
+// a URL or an existing /media/ path. The live playlist itself is untouched
+// and resumes unchanged once the emergency is deleted.
+// Body: { "name": ..., "type": "html"|"image", "html"?: ..., "url"?: ..., "durationSec"?: <n> }
+func handleAdminEmergency(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string `json:"name"`
+		Type        string `json:"type"`
+		HTML        string `json:"html"`
+		URL         string `json:"url"`
+		DurationSec int    `json:"durationSec"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	if body.Type != "html" && body.Type != "image" {
+		http.Error(w, `{"error":"type must be html or image"}`, http.StatusBadRequest)
+		return
+	}
+
+	id := "emergency-" + strconv.FormatInt(time.Now().Unix(), 10)
+	src := strings.TrimSpace(body.URL)
+	if body.Type == "html" && strings.TrimSpace(body.HTML) != "" {
+		fileName := id + ".html"
+		if err := os.WriteFile(filepath.Join(mediaDir, fileName), []byte(sanitizeHTML(body.HTML)), 0o644); err != nil {
+			log.Printf("Emergency: write html failed: %v", err)
+			http.Error(w, `{"error":"could not store html"}`, http.StatusInternalServerError)
+			return
+		}
+		src = "/media/" + fileName
+	}
+	if src == "" {
+		http.Error(w, `{"error":"html or url required"}`, http.StatusBadRequest)
+		return
+	}
+
+	durMs := body.DurationSec * 1000
+	if durMs <= 0 {
+		durMs = dbDefaultDurationMs()
+	}
+	dbSetEmergency(kioskAd{
+		ID:         id,
+		Name:       body.Name,
+		Type:       body.Type,
+		DurationMs: durMs,
+		Src:        src,
+		Transition: adTransition{Enter: "fade", Exit: "fade"},
+	})
+	log.Printf("Emergency: broadcast %q active — all playlists preempted", body.Name)
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "id": id})
+}
+
+// handleAdminClearEmergency ends the broadcast and restores normal rotation.
+func handleAdminClearEmergency(w http.ResponseWriter, r *http.Request) {
+	cleared := dbClearEmergency()
+	if cleared {
+		log.Printf("Emergency: broadcast cleared — normal rotation restored")
+		signalPlaylistChanged()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "cleared": cleared})
+}
+
+// handleAdminSetDefault flags or unflags an ad as part of the default
+// fallback set served when nothing is live.
+// Body: { "isDefault": <bool> }
+func handleAdminSetDefault(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var body struct {
+		IsDefault bool `json:"isDefault"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	if !dbSetDefault(id, body.IsDefault) {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	log.Printf("Admin: ad %q default flag → %v", id, body.IsDefault)
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+// handleAdminPreview streams an ad's media back to the dashboard so admins
+// can review it without opening the raw third-party URL in their own browser.
+// Local /media/ copies are served from disk; anything else is fetched
+// server-side and streamed through with the upstream content type. A
+// restrictive CSP keeps any active content in the response inert, and the
+// same 2 GB cap as submission applies.
+func handleAdminPreview(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	ad, found := dbGetAd(id)
+	if !found {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	if ad.Src == "" {
+		http.Error(w, `{"error":"ad has no media to preview"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src 'self'; media-src 'self'; style-src 'unsafe-inline'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", "inline")
+
+	if strings.HasPrefix(ad.Src, "/media/") {
+		http.ServeFile(w, r, filepath.Join(mediaDir, filepath.Base(ad.Src)))
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodGet, ad.Src, nil)
+	if err != nil {
+		http.Error(w, `{"error":"bad media url"}`, http.StatusBadGateway)
+		return
+	}
+	resp, err := mediaClient.Do(req)
+	if err != nil {
+		log.Printf("Preview: fetch of %s failed: %v", ad.Src, err)
+		http.Error(w, `{"error":"media fetch failed"}`, http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Preview: media server returned %s for %s", resp.Status, ad.Src)
+		http.Error(w, `{"error":"media server error"}`, http.StatusBadGateway)
+		return
+	}
+	if resp.ContentLength > maxPreviewBytes {
+		http.Error(w, `{"error":"media too large"}`, http.StatusRequestEntityTooLarge)
+		return
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	// Stream with a hard cap so an upstream that lies about its length gets
+	// cut off instead of buffered.
+	_, _ = io.Copy(w, io.LimitReader(resp.Body, maxPreviewBytes))
+}
+
+// handleAdminUpdateAd applies a partial in-place edit to any ad, so an admin
+// can fix a slightly-off submission without the submitter deleting and
+// re-submitting (which loses its place in the pipeline).
+// Body: { "name"?: <string>, "durationMs"?: <int> } — duration clamped like
+// handleAdminSetDuration. Returns the updated record.
+func handleAdminUpdateAd(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var body struct {
+		Name       *string `json:"name"`
+		DurationMs *int    `json:"durationMs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	if body.Name == nil && body.DurationMs == nil {
+		http.Error(w, `{"error":"nothing to update"}`, http.StatusBadRequest)
+		return
+	}
+	if body.Name != nil {
+		trimmed := strings.TrimSpace(*body.Name)
+		if trimmed == "" {
+			http.Error(w, `{"error":"name cannot be empty"}`, http.StatusBadRequest)
+			return
+		}
+		body.Name = &trimmed
+	}
+	if body.DurationMs != nil {
+		const minMs, maxMs = 1000, 30000
+		if *body.DurationMs < minMs {
+			*body.DurationMs = minMs
+		}
+		if *body.DurationMs > maxMs {
+			*body.DurationMs = maxMs
+		}
+	}
+	rec, found := dbUpdateAd(id, body.Name, body.DurationMs)
+	if !found {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	log.Printf("Admin: edited ad %q in place", id)
+	// Same rationale as handleAdminSetDuration: signalling for a non-live ad
+	// is a harmless no-op.
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "ad": rec})
+}
+
 // handleRetractMySubmission lets a submitter permanently delete one of their
 // own ads regardless of its current status. The media file is also removed.
 // Query param: email (used as the ownership key, same as other public endpoints).
@@ -817,7 +2254,10 @@ func handleMySubmissions(w http.ResponseWriter, r *http.Request) {
 	email := strings.TrimSpace(r.URL.Query().Get("email"))
 	if email == "" {
 		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode([]any{})
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"submissions": []any{},
+			"quota":       map[string]int{"used": 0, "limit": dbMaxAdsPerUser()},
+		})
 		return
 	}
 
@@ -830,6 +2270,10 @@ func handleMySubmissions(w http.ResponseWriter, r *http.Request) {
 		SubmittedBy string `json:"submittedBy"`
 		SubmittedAt string `json:"submittedAt"`
 		Status      string `json:"status"`
+		DenyReason  string `json:"denyReason,omitempty"`
+		// Who pulled a removed ad — "you retracted this" vs "an admin
+		// removed this".
+		RemovedBy string `json:"removedBy,omitempty"`
 	}
 
 	recs := dbBySubmitter(email)
@@ -844,10 +2288,17 @@ func handleMySubmissions(w http.ResponseWriter, r *http.Request) {
 			SubmittedBy: rec.SubmittedBy,
 			SubmittedAt: rec.SubmittedAt,
 			Status:      rec.Status,
+			DenyReason:  rec.DenyReason,
+			RemovedBy:   rec.RemovedBy,
 		}
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(out)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"submissions": out,
+		// Quota usage so the dashboard can show "3/5 pending ads".
+		// limit 0 means unlimited.
+		"quota": map[string]int{"used": dbPipelineCount(email), "limit": dbMaxAdsPerUser()},
+	})
 }
 
 // handleUploadMedia accepts a base64-encoded media file (image/video) or raw
@@ -913,8 +2364,9 @@ func handleUploadMedia(w http.ResponseWriter, r *http.Request) {
 	var fileBytes []byte
 	switch req.Type {
 	case "html":
-		// HTML is sent as plain UTF-8 text.
-		fileBytes = []byte(req.Data)
+		// HTML is sent as plain UTF-8 text. Sanitize before persisting so a
+		// submitted <script> or event handler never reaches the kiosk web view.
+		fileBytes = []byte(sanitizeHTML(req.Data))
 	default:
 		// Image/video are sent as base64 data URLs:
 		// "data:<mime>;base64,<encoded bytes>"
@@ -937,108 +2389,363 @@ func handleUploadMedia(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"server error"}`, http.StatusInternalServerError)
 		return
 	}
-	if err := os.Rename(tmpPath, destPath); err != nil {
-		_ = os.Remove(tmpPath)
-		log.Printf("Upload: rename failed: %v", err)
-		http.Error(w, `{"error":"server error"}`, http.StatusInternalServerError)
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		_ = os.Remove(tmpPath)
+		log.Printf("Upload: rename failed: %v", err)
+		http.Error(w, `{"error":"server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Upload: saved %s (%d bytes)", filename, len(fileBytes))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"src": "/media/" + filename})
+}
+
+// handleSubmissionStatus is a public endpoint that lets submitters poll the
+// current status of their ads by ID.
+func handleSubmissionStatus(w http.ResponseWriter, r *http.Request) {
+	raw := strings.TrimSpace(r.URL.Query().Get("ids"))
+	if raw == "" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]any{})
+		return
+	}
+	ids := strings.Split(raw, ",")
+	statusMap := dbAllStatuses()
+
+	type item struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	out := make([]item, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		st, ok := statusMap[id]
+		if !ok {
+			st = "unknown"
+		}
+		out = append(out, item{ID: id, Status: st})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func handleAdminApproveSubmitted(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if moderationURL != "" {
+		ad, ok := dbGetAd(id)
+		if !ok {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		verdict, err := moderateAd(ad)
+		if err != nil {
+			// Fail safe: leave the ad in "submitted" rather than approving
+			// content the classifier never saw.
+			log.Printf("Moderation: ad %q not checked: %v", id, err)
+			http.Error(w, `{"error":"moderation service unavailable"}`, http.StatusBadGateway)
+			return
+		}
+		if !verdict.Allowed {
+			dbSetStatus(id, adStatusDenied)
+			log.Printf("Moderation: auto-denied ad %q: %s", id, verdict.Reason)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "denied": true, "reason": verdict.Reason})
+			return
+		}
+	}
+
+	ok, conflict, current := dbTransition(id, adStatusSubmitted, adStatusApproved)
+	if conflict {
+		msg := fmt.Sprintf(`{"error":"ad is %s, not submitted — someone else got there first"}`, current)
+		http.Error(w, msg, http.StatusConflict)
+		return
+	}
+	if !ok {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	log.Printf("Admin: approved submitted ad %q", id)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+func handleAdminActivateApproved(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	ok, evicted, capHit, conflict := dbMoveToLive(id)
+	if conflict {
+		http.Error(w, `{"error":"ad is not in the approved stage"}`, http.StatusConflict)
+		return
+	}
+	if capHit {
+		msg := fmt.Sprintf(`{"error":"live playlist is at its cap of %d ads"}`, dbMaxLiveAds())
+		http.Error(w, msg, http.StatusConflict)
+		return
+	}
+	if !ok {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	if evicted != "" {
+		log.Printf("Admin: evicted oldest live ad %q to stay under the live cap", evicted)
+	}
+	log.Printf("Admin: activated approved ad %q → live", id)
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "evicted": evicted})
+}
+
+// handleAdminDeactivateActive moves a live ad back to the approved (unused) queue.
+func handleAdminDeactivateActive(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !dbMoveBackToApproved(id) {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	log.Printf("Admin: deactivated live ad %q → approved (unused)", id)
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+func handleAdminClearActive(w http.ResponseWriter, r *http.Request) {
+	snapshotForUndo("clear")
+	// Media deletion is deferred: the cleared records live on in the undo
+	// snapshot and their media is reaped when the snapshot is discarded.
+	_, n := dbClearLive()
+	log.Printf("Admin: cleared %d live ad(s) from machine", n)
+	if n > 0 {
+		signalPlaylistChanged()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "cleared": n})
+}
+
+// ─── Undo ─────────────────────────────────────────────────────────────────────
+//
+// One level of undo for the destructive live-set operations (clear, reload).
+// Before each such operation the current live set is snapshotted; POST
+// /api/admin/undo restores it. Media files for cleared ads are NOT deleted
+// immediately — they are reaped only when the snapshot is discarded by the
+// next destructive operation, so an undone clear gets its media back intact.
+
+var (
+	undoMu      sync.Mutex
+	undoRecords []adRecord
+	undoAction  string
+	undoValid   bool
+)
+
+// snapshotForUndo saves the current live set as the undo point for the named
+// destructive action, reaping media deferred by the snapshot it replaces.
+func snapshotForUndo(action string) {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	if undoValid {
+		for _, r := range undoRecords {
+			if _, ok := dbGetAd(r.AdID); !ok {
+				deleteMediaFile(r.Src)
+			}
+		}
+	}
+	undoRecords = dbSnapshotLive()
+	undoAction = action
+	undoValid = true
+}
+
+func undoAvailable() bool {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	return undoValid
+}
+
+// handleAdminUndo restores the live set snapshotted before the most recent
+// clear/reload. Only one level of undo is kept.
+func handleAdminUndo(w http.ResponseWriter, r *http.Request) {
+	undoMu.Lock()
+	recs, action, valid := undoRecords, undoAction, undoValid
+	undoRecords, undoAction, undoValid = nil, "", false
+	undoMu.Unlock()
+
+	if !valid {
+		http.Error(w, `{"error":"nothing to undo"}`, http.StatusConflict)
+		return
+	}
+	dbRestoreLive(recs)
+	log.Printf("Admin: undo %q — restored %d live ad(s)", action, len(recs))
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "undone": action, "restored": len(recs)})
+}
+
+// ─── Named playlist CRUD ──────────────────────────────────────────────────────
+
+func handleAdminListPlaylists(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dbListPlaylists())
+}
+
+// handleAdminSetNamedPlaylist creates or replaces a named playlist.
+// Body: { "ids": ["ad1", "ad2", ...] } — order is preserved.
+func handleAdminSetNamedPlaylist(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.PathValue("name"))
+	if name == "" {
+		http.Error(w, `{"error":"playlist name required"}`, http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	if cap := dbMaxLiveAds(); cap > 0 && len(body.IDs) > cap {
+		msg := fmt.Sprintf(`{"error":"playlist exceeds the cap of %d ads"}`, cap)
+		http.Error(w, msg, http.StatusConflict)
 		return
 	}
+	dbSetPlaylist(name, body.IDs)
+	log.Printf("Admin: playlist %q set (%d ad(s))", name, len(body.IDs))
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
 
-	log.Printf("Upload: saved %s (%d bytes)", filename, len(fileBytes))
+// handleAdminGetSubmissionsOpen reports the submissions toggle on its own —
+// the generic settings endpoint would work too, but the dashboard polls this
+// one flag often enough to deserve a direct answer.
+func handleAdminGetSubmissionsOpen(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"src": "/media/" + filename})
+	_ = json.NewEncoder(w).Encode(map[string]bool{"open": dbSubmissionsOpen()})
 }
 
-// handleSubmissionStatus is a public endpoint that lets submitters poll the
-// current status of their ads by ID.
-func handleSubmissionStatus(w http.ResponseWriter, r *http.Request) {
-	raw := strings.TrimSpace(r.URL.Query().Get("ids"))
-	if raw == "" {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode([]any{})
-		return
+// handleAdminSetSubmissionsOpen flips the submissions toggle.
+// Body: { "open": true|false }
+func handleAdminSetSubmissionsOpen(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Open *bool `json:"open"`
 	}
-	ids := strings.Split(raw, ",")
-	statusMap := dbAllStatuses()
-
-	type item struct {
-		ID     string `json:"id"`
-		Status string `json:"status"`
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Open == nil {
+		http.Error(w, `{"error":"body must be {\"open\": true|false}"}`, http.StatusBadRequest)
+		return
 	}
-	out := make([]item, 0, len(ids))
-	for _, id := range ids {
-		id = strings.TrimSpace(id)
-		if id == "" {
-			continue
-		}
-		st, ok := statusMap[id]
-		if !ok {
-			st = "unknown"
-		}
-		out = append(out, item{ID: id, Status: st})
+	dbSetSetting(settingSubmissionsOpen, strconv.FormatBool(*body.Open))
+	if *body.Open {
+		log.Printf("Admin: submissions reopened")
+	} else {
+		log.Printf("Admin: submissions frozen")
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(out)
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true, "open": *body.Open})
 }
 
-func handleAdminApproveSubmitted(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	if !dbSetStatus(id, adStatusApproved) {
-		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+// handleDisplayConfig is public: the kiosk reads it at startup (and after a
+// restart pushed from the dashboard) to rotate its rendering for portrait
+// screens.
+func handleDisplayConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"rotation": dbDisplayRotation()})
+}
+
+// handleAdminSetDisplayRotation sets the kiosk display rotation.
+// Body: { "rotation": 0|90|180|270 }. Takes effect on the kiosk's next
+// restart (or a pushed restart-kiosk).
+func handleAdminSetDisplayRotation(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Rotation *int `json:"rotation"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Rotation == nil {
+		http.Error(w, `{"error":"body must be {\"rotation\": 0|90|180|270}"}`, http.StatusBadRequest)
 		return
 	}
-	log.Printf("Admin: approved submitted ad %q", id)
+	switch *body.Rotation {
+	case 0, 90, 180, 270:
+	default:
+		http.Error(w, `{"error":"rotation must be 0, 90, 180 or 270"}`, http.StatusBadRequest)
+		return
+	}
+	dbSetSetting(settingDisplayRotation, strconv.Itoa(*body.Rotation))
+	log.Printf("Admin: display rotation set to %d°", *body.Rotation)
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "rotation": *body.Rotation})
 }
 
-func handleAdminActivateApproved(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	if !dbMoveToLive(id) {
-		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+func handleAdminGetSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"entries": dbListSchedule()})
+}
+
+// handleAdminSetSchedule replaces the day-part schedule.
+// Body: { "entries": [ { "start": "08:00", "end": "12:00", "playlist": "morning" }, ... ] }
+// Entries are evaluated in order, first match wins; start > end wraps midnight.
+func handleAdminSetSchedule(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Entries []scheduleRecord `json:"entries"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
 		return
 	}
-	log.Printf("Admin: activated approved ad %q → live", id)
+	for _, e := range body.Entries {
+		if _, err := parseClock(e.Start); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+		if _, err := parseClock(e.End); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+		// Catch typos now rather than serving the live set at the wrong
+		// hour and nobody noticing.
+		if _, ok := dbPlaylistAds(e.Playlist); !ok {
+			http.Error(w, fmt.Sprintf(`{"error":"no playlist named %q"}`, e.Playlist), http.StatusBadRequest)
+			return
+		}
+	}
+	dbSetSchedule(body.Entries)
+	log.Printf("Admin: day-part schedule set (%d entr(y/ies))", len(body.Entries))
 	signalPlaylistChanged()
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 }
 
-// handleAdminDeactivateActive moves a live ad back to the approved (unused) queue.
-func handleAdminDeactivateActive(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	if !dbMoveBackToApproved(id) {
+func handleAdminDeleteNamedPlaylist(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !dbDeletePlaylist(name) {
 		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 		return
 	}
-	log.Printf("Admin: deactivated live ad %q → approved (unused)", id)
+	log.Printf("Admin: playlist %q deleted", name)
 	signalPlaylistChanged()
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 }
 
-func handleAdminClearActive(w http.ResponseWriter, r *http.Request) {
-	cleared, n := dbClearLive()
-	for _, ad := range cleared {
-		deleteMediaFile(ad.Src)
-	}
-	log.Printf("Admin: cleared %d live ad(s) from machine", n)
-	if n > 0 {
-		signalPlaylistChanged()
-	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "cleared": n})
-}
-
 // handleAdminReload moves all approved ads → live then signals the kiosk.
+// With max_live_ads set, the batch is truncated to fit; a reload that can't
+// activate anything because the cap is already reached is a 409.
 func handleAdminReload(w http.ResponseWriter, r *http.Request) {
-	activated := dbMoveApprovedToLive()
+	snapshotForUndo("reload")
+	activated, skipped := dbMoveApprovedToLive()
+	if activated == 0 && skipped > 0 {
+		msg := fmt.Sprintf(`{"error":"live playlist is at its cap of %d ads"}`, dbMaxLiveAds())
+		http.Error(w, msg, http.StatusConflict)
+		return
+	}
+	if skipped > 0 {
+		log.Printf("Admin: reload — %d ad(s) left in approved: live cap is %d", skipped, dbMaxLiveAds())
+	}
 	log.Printf("Admin: reload — %d approved ad(s) pushed live", activated)
 	if activated > 0 {
 		signalPlaylistChanged()
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "activated": activated})
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "activated": activated, "skipped": skipped})
 }
 
 func handleAdminRestartKiosk(w http.ResponseWriter, r *http.Request) {
@@ -1050,9 +2757,10 @@ func handleAdminRestartKiosk(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleNavPoll is called by the kiosk frontend every ~1 s.
-// It blocks up to 2 s waiting for a nav command, then returns.
+// It blocks up to navPollTimeout waiting for a nav command, then returns.
 // Response: {"cmd":"next"}, {"cmd":"prev"}, {"cmd":"refresh"}, or {"cmd":"none"}.
 func handleNavPoll(w http.ResponseWriter, r *http.Request) {
+	touchKioskContact()
 	w.Header().Set("Content-Type", "application/json")
 	select {
 	case cmd := <-navCmdCh:
@@ -1061,37 +2769,151 @@ func handleNavPoll(w http.ResponseWriter, r *http.Request) {
 		// A live-playlist mutation happened — tell the kiosk to re-fetch
 		// immediately instead of waiting for its 60-second polling cycle.
 		_ = json.NewEncoder(w).Encode(map[string]string{"cmd": "refresh"})
-	case <-time.After(2 * time.Second):
+	case <-r.Context().Done():
+		// Kiosk disconnected — free the goroutine instead of holding it
+		// for the remainder of the timeout.
+	case <-time.After(navPollTimeout):
 		_ = json.NewEncoder(w).Encode(map[string]string{"cmd": "none"})
 	}
 }
 
+// playlistVersion counts live-set mutations so handlePlaylist can hand out a
+// cheap ETag without hashing the playlist on every poll.
+var playlistVersion atomic.Int64
+
+// playlistSubs fans live-set change notifications out to SSE subscribers,
+// alongside the single-consumer nav-poll channel.
+var (
+	playlistSubMu sync.Mutex
+	playlistSubs  = map[chan struct{}]struct{}{}
+)
+
+// subscribePlaylistChanges registers a change channel. The returned cancel
+// must be called or the subscription leaks.
+func subscribePlaylistChanges() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	playlistSubMu.Lock()
+	playlistSubs[ch] = struct{}{}
+	playlistSubMu.Unlock()
+	return ch, func() {
+		playlistSubMu.Lock()
+		delete(playlistSubs, ch)
+		playlistSubMu.Unlock()
+	}
+}
+
+// handlePlaylistStream notifies the kiosk of live-set changes over SSE, so
+// it can refetch immediately instead of waiting out a poll interval. Each
+// event carries the current playlist version; the polling endpoint stays for
+// older kiosk builds.
+func handlePlaylistStream(w http.ResponseWriter, r *http.Request) {
+	fl, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, `{"error":"streaming unsupported"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch, cancel := subscribePlaylistChanges()
+	defer cancel()
+
+	send := func() {
+		fmt.Fprintf(w, "event: playlist\ndata: %d\n\n", playlistVersion.Load())
+		fl.Flush()
+	}
+	send() // current state on connect, so a change during reconnect isn't missed
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ch:
+			send()
+		case <-ping.C:
+			// Comment line keeps idle connections alive through proxies.
+			fmt.Fprint(w, ": ping\n\n")
+			fl.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 // signalPlaylistChanged sends a non-blocking notification that the live
 // playlist has changed.  The nav-poll long-poll will pick it up within ~2 s
 // and return {"cmd":"refresh"} to the kiosk frontend.
 func signalPlaylistChanged() {
+	playlistVersion.Add(1)
 	select {
 	case playlistChangedCh <- struct{}{}:
 	default: // a signal is already pending — no need to queue another
 	}
+	playlistSubMu.Lock()
+	for ch := range playlistSubs {
+		select {
+		case ch <- struct{}{}:
+		default: // subscriber already has a pending notification
+		}
+	}
+	playlistSubMu.Unlock()
 }
 
-func handleAdminKioskNext(w http.ResponseWriter, r *http.Request) {
+// queueNavCmd offers a nav command to the kiosk without blocking. The
+// channel holds 8 entries; if an unresponsive kiosk has let it fill up,
+// the command is dropped and the caller is told so — previously these
+// handlers answered {"ok":true} regardless, which made the dashboard
+// buttons look functional while presses went nowhere.
+func queueNavCmd(w http.ResponseWriter, cmd string) {
+	queued := true
 	select {
-	case navCmdCh <- "next":
-	default: // channel full — drop silently
+	case navCmdCh <- cmd:
+	default:
+		queued = false
+		log.Printf("Nav: %q dropped — command queue full (kiosk not polling?)", cmd)
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	_ = json.NewEncoder(w).Encode(map[string]bool{"queued": queued})
+}
+
+func handleAdminKioskNext(w http.ResponseWriter, r *http.Request) {
+	queueNavCmd(w, "next")
 }
 
 func handleAdminKioskPrev(w http.ResponseWriter, r *http.Request) {
-	select {
-	case navCmdCh <- "prev":
-	default:
-	}
+	queueNavCmd(w, "prev")
+}
+
+// handleAdminCheckUpdate is the dry-run counterpart to trigger-update: it
+// performs the release lookup and version comparison only — no updating
+// flag, no kiosk restart — so the dashboard can show "update available" and
+// leave the moment of applying to the operator.
+func handleAdminCheckUpdate(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	release, err := fetchLatestRelease()
+	if err != nil {
+		http.Error(w, `{"error":"release check failed"}`, http.StatusBadGateway)
+		return
+	}
+	current := currentBuildInt()
+	out := map[string]any{
+		"available":    false,
+		"current":      current,
+		"latest":       0,
+		"assetPresent": false,
+	}
+	if release != nil {
+		latest, newer := compareBuilds(current, release.TagName)
+		out["latest"] = latest
+		out["available"] = newer
+		for _, a := range release.Assets {
+			if a.Name == bundleAsset {
+				out["assetPresent"] = true
+				break
+			}
+		}
+	}
+	_ = json.NewEncoder(w).Encode(out)
 }
 
 func handleAdminTriggerUpdate(w http.ResponseWriter, r *http.Request) {
@@ -1106,17 +2928,102 @@ func handleAdminTriggerUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 	exeDir := exeDirectory()
 	go func() {
-		if err := checkAndApply(exeDir); err != nil {
+		if err := checkAndApply(exeDir, true); err != nil {
 			log.Printf("Admin trigger-update: %v", err)
 		}
 	}()
 	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 }
 
+// ─── Export / import ──────────────────────────────────────────────────────────
+
+// pipelineExport is the document shape produced by /api/admin/export and
+// accepted by /api/admin/import.
+type pipelineExport struct {
+	ExportedAt string     `json:"exportedAt"`
+	Build      string     `json:"build"`
+	Ads        []adRecord `json:"ads"`
+}
+
+// handleAdminExport dumps every ad across all statuses (with submitter info)
+// as one JSON document, for backups or migrating to another machine.
+func handleAdminExport(w http.ResponseWriter, r *http.Request) {
+	doc := pipelineExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Build:      BuildNumber,
+		Ads:        dbExportAll(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="shell-ads-export.json"`)
+	_ = json.NewEncoder(w).Encode(doc)
+}
+
+// handleAdminImport ingests an export document, upserting records by ad_id,
+// and reports how many were created vs updated.
+func handleAdminImport(w http.ResponseWriter, r *http.Request) {
+	var doc pipelineExport
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	created, updated := dbImport(doc.Ads)
+	log.Printf("Admin: import — %d created, %d updated", created, updated)
+	signalPlaylistChanged()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "created": created, "updated": updated})
+}
+
+// ─── Settings API ─────────────────────────────────────────────────────────────
+//
+// Operator-tunable settings live in the store's settings map, so they survive
+// restarts — including the self-update re-exec in applyUpdate. The store is
+// held in memory and persisted atomically on every write, so reads are cheap
+// and writes are durable.
+
+func handleAdminGetSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dbAllSettings())
+}
+
+// handleAdminPutSettings upserts the given key/value pairs.
+// Body: { "activation_order": "newest", ... }
+func handleAdminPutSettings(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "bad JSON", http.StatusBadRequest)
+		return
+	}
+	for k, v := range body {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		if k == settingDefaultDurationMs {
+			if n, err := strconv.Atoi(v); err != nil || n < 1000 || n > 30000 {
+				http.Error(w, `{"error":"default_duration_ms must be an integer between 1000 and 30000"}`, http.StatusBadRequest)
+				return
+			}
+		}
+		dbSetSetting(k, v)
+		log.Printf("Admin: setting %q = %q", k, v)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dbAllSettings())
+}
+
 func handleAdminUpdateStatus(w http.ResponseWriter, r *http.Request) {
 	updateStatusMu.RLock()
 	s := updateStatusVal
 	updateStatusMu.RUnlock()
+	nextUpdateCheckMu.RLock()
+	if !nextUpdateCheckAt.IsZero() {
+		s.NextCheckAt = nextUpdateCheckAt.UTC().Format(time.RFC3339)
+	}
+	nextUpdateCheckMu.RUnlock()
+	if prev := dbGetSetting(settingPreviousBuild, ""); prev != "" && prev != BuildNumber {
+		s.PreviousBuild = prev
+		s.UpdatedAt = dbGetSetting(settingLastUpdateAt, "")
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(s)
 }
@@ -1130,8 +3037,10 @@ func monitorKiosk(path string) {
 		}
 
 		cmd := exec.Command(path)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		// Tee kiosk output into the admin-visible ring buffer while keeping
+		// the console copy.
+		cmd.Stdout = io.MultiWriter(os.Stdout, kioskLogs)
+		cmd.Stderr = io.MultiWriter(os.Stderr, kioskLogs)
 		// Point the kiosk at the launcher's local playlist endpoint.
 		// Force-loaded ads from the dashboard Z-button are served here.
 		cmd.Env = append(os.Environ(), "PLAYLIST_URL=http://localhost:6969/api/playlist")
@@ -1183,13 +3092,15 @@ func updateLoop(exeDir string) {
 	}
 
 	log.Printf("Updater: first check in %s", updateCheckDelay)
+	setNextUpdateCheck(time.Now().Add(updateCheckDelay))
 	time.Sleep(updateCheckDelay)
 
 	for {
-		if err := checkAndApply(exeDir); err != nil {
+		if err := checkAndApply(exeDir, false); err != nil {
 			log.Printf("Updater: %v", err)
 		}
 		log.Printf("Updater: next check in %s", updateCheckInterval)
+		setNextUpdateCheck(time.Now().Add(updateCheckInterval))
 		time.Sleep(updateCheckInterval)
 	}
 }
@@ -1199,31 +3110,153 @@ func currentBuildInt() int {
 	return n
 }
 
+// compareBuilds parses a "build-N" release tag and reports whether it is
+// newer than the current build. Malformed or empty tags parse to build 0,
+// which is never newer. Pure, so both the dry-run check and the real apply
+// path share the exact same comparison.
+func compareBuilds(currentBuild int, tag string) (latestBuild int, newer bool) {
+	if after, ok := strings.CutPrefix(tag, "build-"); ok {
+		latestBuild, _ = strconv.Atoi(after)
+	}
+	return latestBuild, latestBuild > currentBuild
+}
+
 // ─── GitHub release helpers ───────────────────────────────────────────────────
 
+// errGitHubRateLimited marks a 403 from the GitHub API so the update stage
+// can say what actually went wrong instead of a generic "could not reach".
+var errGitHubRateLimited = errors.New("GitHub API rate limited")
+
+// errGitHubTransient marks failures worth retrying immediately (5xx,
+// connection resets, timeouts) as opposed to ones that won't improve on a
+// second try within the same check (404, rate limiting).
+var errGitHubTransient = errors.New("transient GitHub error")
+
+// releaseCacheTTL is how long a release lookup is reused. The unauthenticated
+// GitHub API allows 60 req/hr per IP, which several kiosks behind one NAT (or
+// an admin mashing trigger-update) burn through fast.
+const releaseCacheTTL = 5 * time.Minute
+
+var (
+	releaseCacheMu    sync.Mutex
+	cachedRelease     *ghRelease
+	releaseCacheValid bool
+	releaseCachedAt   time.Time
+	// rateLimitResetAt is parsed from X-RateLimit-Reset after an exhausted
+	// 403; no request goes out before it passes. Guarded by releaseCacheMu.
+	rateLimitResetAt time.Time
+)
+
+// fetchLatestRelease returns the newest release on the configured channel,
+// serving from a short-lived cache shared by the auto-loop and the admin
+// trigger. A GITHUB_TOKEN env var is sent as a Bearer token when present to
+// raise the rate limit.
+// Stable uses /releases/latest (GitHub already excludes pre-releases there);
+// beta lists recent releases and takes the newest, pre-release or not.
 func fetchLatestRelease() (*ghRelease, error) {
+	releaseCacheMu.Lock()
+	if releaseCacheValid && time.Since(releaseCachedAt) < releaseCacheTTL {
+		r := cachedRelease
+		releaseCacheMu.Unlock()
+		return r, nil
+	}
+	reset := rateLimitResetAt
+	releaseCacheMu.Unlock()
+	if time.Now().Before(reset) {
+		// Blindly retrying (or letting an admin trigger retry) before the
+		// limit resets just cascades more 403s into the logs.
+		return nil, fmt.Errorf("%w until %s", errGitHubRateLimited, reset.Local().Format("15:04:05"))
+	}
+
+	// One transient network blip shouldn't push the next successful check a
+	// whole hour out, so retry those a couple of times with backoff. 404s
+	// and rate limiting are handled above/below and never retried here.
+	var release *ghRelease
+	var err error
+	backoff := 2 * time.Second
+	for attempt := 1; attempt <= 3; attempt++ {
+		release, err = fetchLatestReleaseUncached()
+		if err == nil || !errors.Is(err, errGitHubTransient) {
+			break
+		}
+		if attempt < 3 {
+			log.Printf("Updater: GitHub check failed (%v) — retrying in %s", err, backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	releaseCacheMu.Lock()
+	cachedRelease = release
+	releaseCacheValid = true
+	releaseCachedAt = time.Now()
+	releaseCacheMu.Unlock()
+	return release, nil
+}
+
+func fetchLatestReleaseUncached() (*ghRelease, error) {
 	url := fmt.Sprintf(
 		"https://api.github.com/repos/%s/%s/releases/latest",
 		githubOwner, githubRepo,
 	)
+	if updateChannel == "beta" {
+		url = fmt.Sprintf(
+			"https://api.github.com/repos/%s/%s/releases?per_page=10",
+			githubOwner, githubRepo,
+		)
+	}
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "ShellNews-Bernard-launcher/"+BuildNumber)
+	if tok := strings.TrimSpace(os.Getenv("GITHUB_TOKEN")); tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
 
-	resp, err := httpClient.Do(req)
+	resp, err := apiClient.Do(req)
 	if err != nil {
-		return nil, err
+		// Client errors here are network-level: resets, timeouts, DNS.
+		return nil, fmt.Errorf("%w: %v", errGitHubTransient, err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusNotFound {
 		return nil, nil
 	}
+	if resp.StatusCode == http.StatusForbidden {
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if sec, parseErr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); parseErr == nil {
+				resetAt := time.Unix(sec, 0)
+				releaseCacheMu.Lock()
+				rateLimitResetAt = resetAt
+				releaseCacheMu.Unlock()
+				log.Printf("Updater: GitHub rate limit exhausted — deferring checks until %s", resetAt.Local().Format("15:04:05"))
+			}
+		}
+		return nil, errGitHubRateLimited
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: GitHub API returned %s", errGitHubTransient, resp.Status)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("GitHub API: %s", resp.Status)
 	}
+
+	if updateChannel == "beta" {
+		var releases []ghRelease
+		if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			return nil, nil
+		}
+		// The list endpoint returns newest-first.
+		return &releases[0], nil
+	}
+
 	var r ghRelease
 	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
 		return nil, err
@@ -1233,7 +3266,50 @@ func fetchLatestRelease() (*ghRelease, error) {
 
 // ─── Check & apply update ────────────────────────────────────────────────────
 
-func checkAndApply(exeDir string) error {
+// parseMaintenanceWindow parses "HH:MM-HH:MM" into minutes past midnight.
+func parseMaintenanceWindow(spec string) (startMin, endMin int, ok bool) {
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	s, err1 := time.Parse("15:04", strings.TrimSpace(parts[0]))
+	e, err2 := time.Parse("15:04", strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return s.Hour()*60 + s.Minute(), e.Hour()*60 + e.Minute(), true
+}
+
+// updatesAllowedNow reports whether an automatic update may apply right now.
+// The optional MAINTENANCE_WINDOW env ("HH:MM-HH:MM" local time, overnight
+// spans like "02:00-05:00" supported) restricts automatic applies to that
+// window so a restart never blanks the screen mid-rush; unset or invalid
+// means anytime. Admin-triggered updates bypass this entirely.
+func updatesAllowedNow() bool {
+	spec := strings.TrimSpace(os.Getenv("MAINTENANCE_WINDOW"))
+	if spec == "" {
+		return true
+	}
+	start, end, ok := parseMaintenanceWindow(spec)
+	if !ok {
+		log.Printf("Updater: invalid MAINTENANCE_WINDOW %q — applying updates anytime", spec)
+		return true
+	}
+	now := time.Now()
+	m := now.Hour()*60 + now.Minute()
+	switch {
+	case start == end:
+		return true
+	case start < end:
+		return m >= start && m < end
+	default: // overnight window
+		return m >= start || m < end
+	}
+}
+
+// checkAndApply looks for a newer build and applies it. force skips the
+// maintenance-window deferral (admin-triggered updates apply immediately).
+func checkAndApply(exeDir string, force bool) error {
 	// CompareAndSwap: atomically transitions false→true.
 	// If it returns false, another goroutine (another admin, or the auto-loop)
 	// already owns the update slot — bail out immediately.
@@ -1247,7 +3323,11 @@ func checkAndApply(exeDir string) error {
 
 	release, err := fetchLatestRelease()
 	if err != nil {
-		setUpdateStage("error", "Could not reach GitHub.", "", err.Error())
+		msg := "Could not reach GitHub."
+		if errors.Is(err, errGitHubRateLimited) {
+			msg = "GitHub rate limited — set GITHUB_TOKEN or wait before retrying."
+		}
+		setUpdateStage("error", msg, "", err.Error())
 		return fmt.Errorf("fetch release: %w", err)
 	}
 	if release == nil {
@@ -1256,14 +3336,10 @@ func checkAndApply(exeDir string) error {
 		return nil
 	}
 
-	latestBuild := 0
 	latestTag := release.TagName
-	if after, ok := strings.CutPrefix(release.TagName, "build-"); ok {
-		latestBuild, _ = strconv.Atoi(after)
-	}
-
 	currentBuild := currentBuildInt()
-	if latestBuild <= currentBuild {
+	latestBuild, newer := compareBuilds(currentBuild, latestTag)
+	if !newer {
 		setUpdateStage("up_to_date",
 			fmt.Sprintf("Already on the latest build (%s).", BuildNumber),
 			latestTag, "")
@@ -1273,11 +3349,22 @@ func checkAndApply(exeDir string) error {
 
 	log.Printf("Updater: update available build-%d → build-%d", currentBuild, latestBuild)
 
-	var downloadURL string
+	if !force && !updatesAllowedNow() {
+		msg := fmt.Sprintf("Update to %s deferred until maintenance window (%s).", latestTag, strings.TrimSpace(os.Getenv("MAINTENANCE_WINDOW")))
+		setUpdateStage("deferred", msg, latestTag, "")
+		log.Printf("Updater: %s", msg)
+		return nil
+	}
+
+	var downloadURL, sigURL string
+	var assetSize int64
 	for _, a := range release.Assets {
-		if a.Name == bundleAsset {
+		switch a.Name {
+		case bundleAsset:
 			downloadURL = a.BrowserDownloadURL
-			break
+			assetSize = a.Size
+		case sigAsset:
+			sigURL = a.BrowserDownloadURL
 		}
 	}
 	if downloadURL == "" {
@@ -1285,11 +3372,45 @@ func checkAndApply(exeDir string) error {
 		setUpdateStage("error", e.Error(), latestTag, e.Error())
 		return e
 	}
+	if signatureRequired() && sigURL == "" {
+		e := fmt.Errorf("release %s has no %s — refusing unsigned bundle", latestTag, sigAsset)
+		setUpdateStage("error", e.Error(), latestTag, e.Error())
+		return e
+	}
+
+	// Writability preflight: on locked-down installs the exe directory can
+	// be read-only for the service account, and an update would download and
+	// extract everything only to fail at the final swap — with the kiosk
+	// already stopped. Probe before touching anything so a non-updatable
+	// kiosk keeps running.
+	if err := checkDirWritable(exeDir); err != nil {
+		e := fmt.Errorf("exe directory %s is not writable: %w", exeDir, err)
+		setUpdateStage("error", e.Error(), latestTag, e.Error())
+		return e
+	}
+
+	// Disk-space preflight: an update needs roughly 3× the asset size
+	// (download + extracted copy + swap headroom). Bail out before
+	// downloading rather than failing midway on a nearly-full disk.
+	if assetSize > 0 {
+		need := uint64(assetSize) * 3
+		for _, dir := range []string{os.TempDir(), exeDir} {
+			free, err := diskFreeBytes(dir)
+			if err != nil {
+				continue // best-effort: a no-op off Windows
+			}
+			if free < need {
+				e := fmt.Errorf("not enough disk space on %s: %d MB free, need ~%d MB", dir, free>>20, need>>20)
+				setUpdateStage("error", e.Error(), latestTag, e.Error())
+				return e
+			}
+		}
+	}
 
-	return applyUpdate(exeDir, downloadURL, latestTag)
+	return applyUpdate(exeDir, downloadURL, sigURL, latestTag)
 }
 
-func applyUpdate(exeDir, downloadURL, latestTag string) error {
+func applyUpdate(exeDir, downloadURL, sigURL, latestTag string) error {
 	// Note: the updating flag is already set by checkAndApply — do not touch it here.
 
 	// ── 1. Download bundle zip ───────────────────────────────────────────────
@@ -1306,7 +3427,7 @@ func applyUpdate(exeDir, downloadURL, latestTag string) error {
 	req, _ := http.NewRequest(http.MethodGet, downloadURL, nil)
 	req.Header.Set("User-Agent", "ShellNews-Bernard-launcher/"+BuildNumber)
 
-	resp, err := httpClient.Do(req)
+	resp, err := mediaClient.Do(req)
 	if err != nil {
 		setUpdateStage("error", "Download failed.", latestTag, err.Error())
 		return fmt.Errorf("download: %w", err)
@@ -1318,13 +3439,31 @@ func applyUpdate(exeDir, downloadURL, latestTag string) error {
 		return e
 	}
 
-	if _, err := io.Copy(tmpZip, resp.Body); err != nil {
+	// Content-Length is set by GitHub's CDN; when missing the bar simply
+	// stays at 0 and the stage text still shows activity.
+	pr := &progressReader{r: resp.Body, total: resp.ContentLength, onProgress: setUpdateProgress}
+	if _, err := io.Copy(tmpZip, pr); err != nil {
 		setUpdateStage("error", "Failed writing download.", latestTag, err.Error())
 		return fmt.Errorf("write zip: %w", err)
 	}
 	tmpZip.Close()
 	log.Printf("Updater: download complete")
 
+	// Authenticity gate: stamped builds only accept bundles whose detached
+	// Ed25519 signature matches the embedded public key.
+	if signatureRequired() {
+		sig, err := fetchSignature(sigURL)
+		if err != nil {
+			setUpdateStage("error", "Could not download bundle signature.", latestTag, err.Error())
+			return fmt.Errorf("fetch signature: %w", err)
+		}
+		if err := verifyBundleSignature(tmpZip.Name(), sig); err != nil {
+			setUpdateStage("error", "Bundle signature rejected: "+err.Error(), latestTag, err.Error())
+			return fmt.Errorf("verify signature: %w", err)
+		}
+		log.Printf("Updater: bundle signature verified")
+	}
+
 	// ── 2. Extract to temp dir ───────────────────────────────────────────────
 	setUpdateStage("applying", fmt.Sprintf("Installing %s…", latestTag), latestTag, "")
 
@@ -1341,13 +3480,21 @@ func applyUpdate(exeDir, downloadURL, latestTag string) error {
 	}
 	log.Printf("Updater: extracted bundle")
 
+	// Validate the bundle before touching the running kiosk: a partial or
+	// wrong-arch bundle must abort here, while the old kiosk keeps running.
+	if err := validateBundle(tmpDir); err != nil {
+		setUpdateStage("error", "Bundle failed validation: "+err.Error(), latestTag, err.Error())
+		return fmt.Errorf("validate bundle: %w", err)
+	}
+	log.Printf("Updater: bundle validated")
+
 	// ── 3. Stop kiosk, replace kiosk.exe ────────────────────────────────────
 	stopKiosk()
 	time.Sleep(postKillDelay)
 
 	newKiosk := filepath.Join(tmpDir, "kiosk.exe")
 	if _, err := os.Stat(newKiosk); err == nil {
-		if err := copyFile(newKiosk, filepath.Join(exeDir, "kiosk.exe")); err != nil {
+		if err := replaceExecutableRetry(newKiosk, filepath.Join(exeDir, "kiosk.exe")); err != nil {
 			setUpdateStage("error", "Failed replacing kiosk.exe.", latestTag, err.Error())
 			return fmt.Errorf("replace kiosk.exe: %w", err)
 		}
@@ -1364,6 +3511,11 @@ func applyUpdate(exeDir, downloadURL, latestTag string) error {
 	}
 	defer launcherFile.Close()
 
+	// Persist what we're updating away from while the old binary still
+	// knows it; the replacement reads this back for the dashboard.
+	dbSetSetting(settingPreviousBuild, BuildNumber)
+	dbSetSetting(settingLastUpdateAt, time.Now().UTC().Format(time.RFC3339))
+
 	log.Printf("Updater: applying self-update to launcher.exe…")
 	if err := selfupdate.Apply(launcherFile, selfupdate.Options{}); err != nil {
 		log.Printf("Updater: self-update failed: %v — continuing", err)
@@ -1373,7 +3525,12 @@ func applyUpdate(exeDir, downloadURL, latestTag string) error {
 
 	setUpdateStage("restarting", fmt.Sprintf("Restarting with %s…", latestTag), latestTag, "")
 	log.Printf("Updater: restarting launcher…")
-	log.Printf("Updater: restarting launcher...")
+	// Drain the dashboard server first so nobody gets a truncated response
+	// right before the listener vanishes.
+	shutdownDashServer(5 * time.Second)
+	// Hand the single-instance lock over before spawning the replacement so
+	// it never sees us as a still-running instance.
+	releaseInstanceLock()
 	newCmd := exec.Command(os.Args[0], os.Args[1:]...)
 	newCmd.Stdout = os.Stdout
 	newCmd.Stderr = os.Stderr
@@ -1385,6 +3542,25 @@ func applyUpdate(exeDir, downloadURL, latestTag string) error {
 	return nil // unreachable
 }
 
+// validateBundle confirms the extracted bundle contains both kiosk.exe and
+// launcher.exe, non-empty and starting with the PE magic bytes ("MZ"). Called
+// before stopKiosk so a bad bundle can never half-apply.
+func validateBundle(dir string) error {
+	for _, name := range []string{"kiosk.exe", "launcher.exe"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("bundle missing %s", name)
+		}
+		var magic [2]byte
+		_, readErr := io.ReadFull(f, magic[:])
+		f.Close()
+		if readErr != nil || magic[0] != 'M' || magic[1] != 'Z' {
+			return fmt.Errorf("%s is not a valid Windows executable", name)
+		}
+	}
+	return nil
+}
+
 // ─── Filesystem helpers ───────────────────────────────────────────────────────
 
 func exeDirectory() string {
@@ -1415,6 +3591,61 @@ func copyFile(src, dst string) error {
 	return err
 }
 
+// replaceExecutable swaps dst with src crash-safely: the new binary is staged
+// as dst+".new", the previous one is kept as dst+".old" for rollback, and the
+// final step is a same-volume os.Rename — atomic, so a power loss mid-update
+// leaves either the old or the new binary bootable, never a truncated one.
+// This mirrors what minio/selfupdate does for the launcher itself.
+func replaceExecutable(src, dst string) error {
+	newPath := dst + ".new"
+	if err := copyFile(src, newPath); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("stage new binary: %w", err)
+	}
+	oldPath := dst + ".old"
+	_ = os.Remove(oldPath)
+	if _, err := os.Stat(dst); err == nil {
+		if err := os.Rename(dst, oldPath); err != nil {
+			os.Remove(newPath)
+			return fmt.Errorf("set aside old binary: %w", err)
+		}
+	}
+	if err := os.Rename(newPath, dst); err != nil {
+		// Put the previous binary back so the kiosk can still boot.
+		_ = os.Rename(oldPath, dst)
+		os.Remove(newPath)
+		return fmt.Errorf("activate new binary: %w", err)
+	}
+	return nil
+}
+
+// checkDirWritable probes dir by creating and removing a temp file.
+func checkDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, "write-probe-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// replaceExecutableRetry wraps replaceExecutable with a few backoff retries.
+// On Windows the just-killed kiosk can hold its binary lock a beat longer
+// than postKillDelay, surfacing as a sharing-violation/permission error —
+// retrying is what saves the whole update from failing on slow hardware.
+func replaceExecutableRetry(src, dst string) error {
+	var err error
+	for attempt, wait := 0, postKillDelay; attempt < 4; attempt, wait = attempt+1, wait*2 {
+		if err = replaceExecutable(src, dst); err == nil {
+			return nil
+		}
+		log.Printf("Updater: replace %s attempt %d failed (%v) — retrying in %s", filepath.Base(dst), attempt+1, err, wait)
+		time.Sleep(wait)
+	}
+	return err
+}
+
 func extractZip(src, dst string) error {
 	r, err := zip.OpenReader(src)
 	if err != nil {
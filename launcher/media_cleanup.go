@@ -0,0 +1,128 @@
+package main
+
+// media_cleanup.go — bounded on-disk media cache.
+//
+// Downloaded ad media accumulates forever by default, and a long-running
+// kiosk can quietly fill its disk. MEDIA_MAX_BYTES caps the total size of
+// the media directory; when it is exceeded, the least-recently-used files
+// are deleted — but only files whose ad is not currently live (nor an
+// emergency broadcast or default-set member), so nothing on screen ever
+// loses its backing file. Recency comes from file mtime, which the /media/
+// handler touches on every serve.
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const mediaCleanupInterval = time.Hour
+
+// mediaMaxBytes returns the configured cap, or 0 for unlimited.
+func mediaMaxBytes() int64 {
+	v := strings.TrimSpace(os.Getenv("MEDIA_MAX_BYTES"))
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("Media: invalid MEDIA_MAX_BYTES %q — cap disabled", v)
+		return 0
+	}
+	return n
+}
+
+// mediaCleanupLoop enforces the cap periodically. Run as a goroutine.
+func mediaCleanupLoop() {
+	if mediaMaxBytes() == 0 {
+		return
+	}
+	for {
+		if deleted, freed := cleanupMedia(); deleted > 0 {
+			log.Printf("Media: cleanup deleted %d file(s), freed %d MB", deleted, freed>>20)
+		}
+		time.Sleep(mediaCleanupInterval)
+	}
+}
+
+// protectedMediaFiles returns the base names of media files that must never
+// be deleted: everything live, the emergency broadcast, and the default set.
+func protectedMediaFiles() map[string]bool {
+	keep := map[string]bool{}
+	add := func(ads []kioskAd) {
+		for _, ad := range ads {
+			if strings.HasPrefix(ad.Src, "/media/") {
+				keep[filepath.Base(ad.Src)] = true
+			}
+		}
+	}
+	add(dbLiveOrdered())
+	add(dbDefaultAds())
+	if em, ok := dbEmergencyAd(); ok {
+		add([]kioskAd{em})
+	}
+	return keep
+}
+
+// cleanupMedia deletes LRU non-protected files until total usage fits the
+// cap. Returns how many files were removed and the bytes freed.
+func cleanupMedia() (int, int64) {
+	cap := mediaMaxBytes()
+	if cap == 0 || mediaDir == "" {
+		return 0, 0
+	}
+	entries, err := os.ReadDir(mediaDir)
+	if err != nil {
+		return 0, 0
+	}
+
+	type mediaFile struct {
+		name  string
+		size  int64
+		mtime time.Time
+	}
+	var files []mediaFile
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		total += info.Size()
+		files = append(files, mediaFile{e.Name(), info.Size(), info.ModTime()})
+	}
+	if total <= cap {
+		return 0, 0
+	}
+
+	keep := protectedMediaFiles()
+	sort.Slice(files, func(i, j int) bool { return files[i].mtime.Before(files[j].mtime) })
+
+	deleted := 0
+	var freed int64
+	for _, f := range files {
+		if total <= cap {
+			break
+		}
+		if keep[f.name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(mediaDir, f.name)); err != nil {
+			continue
+		}
+		total -= f.size
+		freed += f.size
+		deleted++
+	}
+	if total > cap {
+		log.Printf("Media: still %d MB over cap after cleanup — everything remaining is live-protected", (total-cap)>>20)
+	}
+	return deleted, freed
+}
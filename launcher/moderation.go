@@ -0,0 +1,74 @@
+package main
+
+// moderation.go — optional external content-moderation hook.
+//
+// When MODERATION_URL is set, approving a submitted ad first POSTs the ad's
+// media reference to that endpoint (e.g. an NSFW/text classifier) and only
+// completes the approval when it answers {"allowed":true}. A {"allowed":false}
+// answer auto-denies the ad with the returned reason. When the service is
+// unreachable we fail safe: the ad stays in "submitted" and the admin gets an
+// error, rather than approving unmoderated content.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// moderationURL is read once at startup; empty disables the hook entirely.
+var moderationURL = strings.TrimSpace(os.Getenv("MODERATION_URL"))
+
+// moderationTimeout bounds the synchronous call so a hung classifier can't
+// hold the approve request forever.
+const moderationTimeout = 5 * time.Second
+
+// moderationResult is the JSON shape the moderation endpoint must return.
+type moderationResult struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// moderateAd submits the ad to the configured moderation endpoint and returns
+// its verdict. A nil error with Allowed=false is an explicit deny; a non-nil
+// error means the service could not be consulted at all.
+func moderateAd(ad kioskAd) (moderationResult, error) {
+	payload, err := json.Marshal(map[string]string{
+		"id":   ad.ID,
+		"name": ad.Name,
+		"type": ad.Type,
+		"src":  ad.Src,
+	})
+	if err != nil {
+		return moderationResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), moderationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, moderationURL, bytes.NewReader(payload))
+	if err != nil {
+		return moderationResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.Do(req)
+	if err != nil {
+		return moderationResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return moderationResult{}, fmt.Errorf("moderation service returned %s", resp.Status)
+	}
+
+	var verdict moderationResult
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return moderationResult{}, fmt.Errorf("decode moderation response: %w", err)
+	}
+	return verdict, nil
+}
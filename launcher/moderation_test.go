@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// approveViaHandler runs handleAdminApproveSubmitted for the given ad ID and
+// returns the recorded response.
+func approveViaHandler(t *testing.T, id string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/submitted/"+id+"/approve", nil)
+	req.SetPathValue("id", id)
+	rec := httptest.NewRecorder()
+	handleAdminApproveSubmitted(rec, req)
+	return rec
+}
+
+func TestModerationHook(t *testing.T) {
+	seed := func() []adRecord {
+		return []adRecord{
+			{AdID: "ad1", Name: "ad1", AdType: "image", Src: "http://x/a.png", Status: adStatusSubmitted},
+		}
+	}
+
+	t.Run("allow completes approval", func(t *testing.T) {
+		resetStore(t, seed())
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(moderationResult{Allowed: true})
+		}))
+		defer srv.Close()
+		moderationURL = srv.URL
+		defer func() { moderationURL = "" }()
+
+		if rec := approveViaHandler(t, "ad1"); rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		if ad, _ := dbGetAd("ad1"); dbAllStatuses()["ad1"] != adStatusApproved {
+			t.Fatalf("expected approved, got %v (%+v)", dbAllStatuses()["ad1"], ad)
+		}
+	})
+
+	t.Run("deny auto-denies with reason", func(t *testing.T) {
+		resetStore(t, seed())
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(moderationResult{Allowed: false, Reason: "nsfw"})
+		}))
+		defer srv.Close()
+		moderationURL = srv.URL
+		defer func() { moderationURL = "" }()
+
+		rec := approveViaHandler(t, "ad1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		var body struct {
+			Denied bool   `json:"denied"`
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if !body.Denied || body.Reason != "nsfw" {
+			t.Fatalf("expected denied with reason, got %+v", body)
+		}
+		if dbAllStatuses()["ad1"] != adStatusDenied {
+			t.Fatalf("expected denied status, got %s", dbAllStatuses()["ad1"])
+		}
+	})
+
+	t.Run("unreachable leaves ad submitted", func(t *testing.T) {
+		resetStore(t, seed())
+		srv := httptest.NewServer(nil)
+		srv.Close() // immediately closed — connection refused
+		moderationURL = srv.URL
+		defer func() { moderationURL = "" }()
+
+		if rec := approveViaHandler(t, "ad1"); rec.Code != http.StatusBadGateway {
+			t.Fatalf("expected 502, got %d", rec.Code)
+		}
+		if dbAllStatuses()["ad1"] != adStatusSubmitted {
+			t.Fatalf("expected ad to stay submitted, got %s", dbAllStatuses()["ad1"])
+		}
+	})
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// killOrphanKiosks is a no-op off Windows — production kiosks only run the
+// Windows binary, and dev machines have no kiosk.exe to reap.
+func killOrphanKiosks(kioskPath string) int {
+	return 0
+}
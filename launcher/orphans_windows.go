@@ -0,0 +1,72 @@
+//go:build windows
+
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// killOrphanKiosks terminates any kiosk.exe left over from a previous
+// launcher run (a crash, or an update re-exec racing the old process).
+// Processes are matched by full executable path, not just image name, so an
+// unrelated kiosk.exe elsewhere on the machine is never touched. Returns how
+// many processes were terminated.
+func killOrphanKiosks(kioskPath string) int {
+	want, err := filepath.Abs(kioskPath)
+	if err != nil {
+		return 0
+	}
+
+	snap, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		log.Printf("Orphans: process snapshot failed: %v", err)
+		return 0
+	}
+	defer windows.CloseHandle(snap)
+
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	killed := 0
+	for err := windows.Process32First(snap, &entry); err == nil; err = windows.Process32Next(snap, &entry) {
+		name := windows.UTF16ToString(entry.ExeFile[:])
+		if !strings.EqualFold(name, filepath.Base(want)) {
+			continue
+		}
+		if !strings.EqualFold(processImagePath(entry.ProcessID), want) {
+			continue
+		}
+		h, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, entry.ProcessID)
+		if err != nil {
+			log.Printf("Orphans: cannot open pid %d: %v", entry.ProcessID, err)
+			continue
+		}
+		if err := windows.TerminateProcess(h, 1); err == nil {
+			killed++
+		} else {
+			log.Printf("Orphans: terminate pid %d failed: %v", entry.ProcessID, err)
+		}
+		windows.CloseHandle(h)
+	}
+	return killed
+}
+
+// processImagePath returns the full executable path for a pid, or "" when it
+// cannot be queried.
+func processImagePath(pid uint32) string {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(h)
+	var buf [windows.MAX_PATH]uint16
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(h, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return windows.UTF16ToString(buf[:size])
+}
@@ -0,0 +1,190 @@
+package main
+
+// playback.go — what the kiosk actually showed, and when.
+//
+// Every heartbeat carries the current ad ID; recording just the transitions
+// gives a timeline that answers "what played between 2pm and 3pm" without
+// logging each repeat poll. The log is in-memory and bounded — it's an
+// operational record, not billing-grade storage, so losing it on restart is
+// acceptable and keeping it out of ads.json keeps saveStore cheap.
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Entries older than this are pruned; a day covers "yesterday afternoon"
+	// questions without unbounded growth.
+	playbackRetention = 24 * time.Hour
+	// Hard cap in case a misbehaving kiosk flaps between ads every second.
+	playbackMaxEntries = 10000
+)
+
+// playbackEntry marks the moment the kiosk switched to an ad. An empty AdID
+// means it went idle (or to the fallback screen).
+type playbackEntry struct {
+	AdID string    `json:"adId"`
+	At   time.Time `json:"at"`
+}
+
+var (
+	playbackMu  sync.Mutex
+	playbackLog []playbackEntry
+)
+
+// recordPlayback appends a transition when the reported ad differs from the
+// last recorded one, pruning anything past retention while it holds the lock.
+func recordPlayback(adID string) {
+	now := time.Now()
+	playbackMu.Lock()
+	defer playbackMu.Unlock()
+	if n := len(playbackLog); n > 0 && playbackLog[n-1].AdID == adID {
+		return
+	}
+	playbackLog = append(playbackLog, playbackEntry{AdID: adID, At: now})
+
+	cutoff := now.Add(-playbackRetention)
+	drop := 0
+	for drop < len(playbackLog) && playbackLog[drop].At.Before(cutoff) {
+		drop++
+	}
+	if over := len(playbackLog) - playbackMaxEntries; over > drop {
+		drop = over
+	}
+	if drop > 0 {
+		playbackLog = append([]playbackEntry(nil), playbackLog[drop:]...)
+	}
+}
+
+// playbackSince returns the transitions at or after the given time, oldest
+// first. A zero time returns everything retained.
+func playbackSince(since time.Time) []playbackEntry {
+	playbackMu.Lock()
+	defer playbackMu.Unlock()
+	i := 0
+	for i < len(playbackLog) && playbackLog[i].At.Before(since) {
+		i++
+	}
+	out := make([]playbackEntry, len(playbackLog)-i)
+	copy(out, playbackLog[i:])
+	return out
+}
+
+// handleAdminPlaybackReport aggregates the playback log into per-ad play
+// counts and on-screen seconds for a date range, as CSV — the shape
+// operators hand to advertisers as proof-of-play. Rows stream out as they
+// are written rather than building the document in memory.
+// ?from= and ?to= take RFC3339; they default to the retention window.
+func handleAdminPlaybackReport(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	from, to := now.Add(-playbackRetention), now
+	if s := r.URL.Query().Get("from"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			http.Error(w, `{"error":"from must be RFC3339"}`, http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if s := r.URL.Query().Get("to"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			http.Error(w, `{"error":"to must be RFC3339"}`, http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+
+	type playStat struct {
+		plays int
+		total time.Duration
+	}
+	stats := map[string]*playStat{}
+	entries := playbackSince(time.Time{})
+	for i, e := range entries {
+		if e.AdID == "" {
+			continue // idle segments don't bill
+		}
+		end := now
+		if i+1 < len(entries) {
+			end = entries[i+1].At
+		}
+		// Clip the segment to the requested range.
+		start := e.At
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		st := stats[e.AdID]
+		if st == nil {
+			st = &playStat{}
+			stats[e.AdID] = st
+		}
+		st.plays++
+		st.total += end.Sub(start)
+	}
+
+	// One pass over the store for names/submitters instead of a lookup per row.
+	meta := map[string]adRecord{}
+	for _, rec := range dbExportAll() {
+		meta[rec.AdID] = rec
+	}
+
+	ids := make([]string, 0, len(stats))
+	for id := range stats {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if stats[ids[i]].plays != stats[ids[j]].plays {
+			return stats[ids[i]].plays > stats[ids[j]].plays
+		}
+		return ids[i] < ids[j]
+	})
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="proof-of-play.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"adId", "name", "submitter", "plays", "totalSeconds"})
+	for _, id := range ids {
+		rec := meta[id]
+		_ = cw.Write([]string{
+			id,
+			rec.Name,
+			rec.SubmittedBy,
+			strconv.Itoa(stats[id].plays),
+			strconv.Itoa(int(stats[id].total.Seconds())),
+		})
+		cw.Flush()
+	}
+	if err := cw.Error(); err != nil {
+		log.Printf("Playback: report stream aborted: %v", err)
+	}
+}
+
+// handleAdminPlaybackHistory returns the playback timeline.
+// Optional ?since= takes an RFC3339 timestamp.
+func handleAdminPlaybackHistory(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			http.Error(w, `{"error":"since must be RFC3339"}`, http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"entries": playbackSince(since)})
+}
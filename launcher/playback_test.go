@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/csv"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecordPlayback(t *testing.T) {
+	playbackMu.Lock()
+	playbackLog = nil
+	playbackMu.Unlock()
+
+	// Repeat heartbeats for the same ad collapse into one transition.
+	recordPlayback("a")
+	recordPlayback("a")
+	recordPlayback("b")
+	recordPlayback("b")
+	recordPlayback("") // idle
+	recordPlayback("a")
+
+	got := playbackSince(time.Time{})
+	wantIDs := []string{"a", "b", "", "a"}
+	if len(got) != len(wantIDs) {
+		t.Fatalf("got %d entries, want %d", len(got), len(wantIDs))
+	}
+	for i, w := range wantIDs {
+		if got[i].AdID != w {
+			t.Fatalf("entry %d: got %q, want %q", i, got[i].AdID, w)
+		}
+	}
+
+	// since= filters out older transitions.
+	if rest := playbackSince(got[2].At); len(rest) != 2 {
+		t.Fatalf("since filter: got %d entries, want 2", len(rest))
+	}
+
+	// Old entries are pruned once past retention.
+	playbackMu.Lock()
+	playbackLog[0].At = time.Now().Add(-playbackRetention - time.Hour)
+	playbackMu.Unlock()
+	recordPlayback("c")
+	got = playbackSince(time.Time{})
+	if got[0].AdID != "b" {
+		t.Fatalf("expected expired head pruned, log starts with %q", got[0].AdID)
+	}
+}
+
+func TestPlaybackReport(t *testing.T) {
+	resetStore(t, []adRecord{
+		{AdID: "a", Name: "Ad A", SubmittedBy: "Alice <alice@example.com>", Status: adStatusLive},
+		{AdID: "b", Name: "Ad B", SubmittedBy: "Bob <bob@example.com>", Status: adStatusLive},
+	})
+	base := time.Now().Add(-time.Hour)
+	playbackMu.Lock()
+	playbackLog = []playbackEntry{
+		{AdID: "a", At: base},
+		{AdID: "b", At: base.Add(10 * time.Second)},
+		{AdID: "a", At: base.Add(30 * time.Second)},
+		{AdID: "", At: base.Add(45 * time.Second)}, // idle — excluded
+	}
+	playbackMu.Unlock()
+
+	url := "/api/admin/report.csv?from=" + base.UTC().Format(time.RFC3339) +
+		"&to=" + base.Add(time.Minute).UTC().Format(time.RFC3339)
+	rr := httptest.NewRecorder()
+	handleAdminPlaybackReport(rr, httptest.NewRequest("GET", url, nil))
+	if rr.Code != 200 {
+		t.Fatalf("got %d", rr.Code)
+	}
+	rows, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Header + one row per ad; "a" played twice so it sorts first.
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows: %v", len(rows), rows)
+	}
+	if rows[1][0] != "a" || rows[1][3] != "2" || rows[1][4] != "25" {
+		t.Fatalf("row for a: %v", rows[1])
+	}
+	if rows[2][0] != "b" || rows[2][1] != "Ad B" || rows[2][3] != "1" || rows[2][4] != "20" {
+		t.Fatalf("row for b: %v", rows[2])
+	}
+}
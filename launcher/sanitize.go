@@ -0,0 +1,166 @@
+package main
+
+// sanitize.go — allowlist-based sanitizer for submitted HTML ads.
+//
+// HTML-type ads are rendered verbatim by the kiosk web view, so a malicious
+// submitter could include <script> that phones home or navigates the kiosk
+// away. Raw HTML enters the system through /api/upload-media, which runs
+// every html upload through sanitizeHTML before persisting it.
+//
+// The sanitizer keeps only allowlisted tags, drops the entire contents of
+// script-like containers, strips on* event-handler attributes, and neuters
+// javascript: URLs. Operators can extend the tag allowlist (never the
+// attribute rules) via the HTML_TAG_ALLOWLIST env var (comma-separated).
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// defaultAllowedTags is the baseline set of tags an HTML ad may use. Ads are
+// full mini-pages, so document structure and styling tags are included;
+// anything that can execute or embed foreign content is not.
+var defaultAllowedTags = map[string]bool{
+	"html": true, "head": true, "title": true, "meta": true, "body": true,
+	"div": true, "span": true, "p": true, "br": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"a": true, "b": true, "i": true, "u": true, "em": true, "strong": true,
+	"ul": true, "ol": true, "li": true,
+	"table": true, "thead": true, "tbody": true, "tr": true, "th": true, "td": true,
+	"img": true, "picture": true, "source": true, "video": true, "audio": true,
+	"figure": true, "figcaption": true,
+	"header": true, "footer": true, "main": true, "section": true, "article": true,
+	"style": true,
+}
+
+// skipContentTags are containers whose children must be dropped entirely when
+// the tag itself is disallowed — keeping a script's text would be as bad as
+// keeping the tag.
+var skipContentTags = map[string]bool{
+	"script": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+}
+
+// allowedTags returns the effective tag allowlist: the defaults plus any
+// extras from HTML_TAG_ALLOWLIST.
+func allowedTags() map[string]bool {
+	tags := make(map[string]bool, len(defaultAllowedTags))
+	for t := range defaultAllowedTags {
+		tags[t] = true
+	}
+	for _, extra := range strings.Split(os.Getenv("HTML_TAG_ALLOWLIST"), ",") {
+		extra = strings.ToLower(strings.TrimSpace(extra))
+		if extra != "" && !skipContentTags[extra] {
+			tags[extra] = true
+		}
+	}
+	return tags
+}
+
+// safeAttr reports whether an attribute may be kept: no event handlers and no
+// javascript: (or similar scheme-smuggling) URL values.
+func safeAttr(key, val string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "on") {
+		return false
+	}
+	v := strings.ToLower(strings.TrimSpace(val))
+	// Strip control characters browsers ignore when parsing schemes.
+	v = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, v)
+	if strings.HasPrefix(v, "javascript:") || strings.HasPrefix(v, "vbscript:") {
+		return false
+	}
+	return true
+}
+
+// sanitizeHTML rewrites markup keeping only allowlisted tags and safe
+// attributes. Disallowed tags are dropped; script-like containers are dropped
+// together with their contents. Text is preserved.
+func sanitizeHTML(markup string) string {
+	tags := allowedTags()
+	tok := html.NewTokenizer(strings.NewReader(markup))
+	var b strings.Builder
+	skipDepth := 0
+	inStyle := false // style is a raw-text element: its CSS must not be entity-escaped
+
+	for {
+		tt := tok.Next()
+		if tt == html.ErrorToken {
+			return b.String()
+		}
+		t := tok.Token()
+		name := strings.ToLower(t.Data)
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if skipContentTags[name] && !tags[name] {
+				if tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 || !tags[name] {
+				continue
+			}
+			if name == "style" && tt == html.StartTagToken {
+				inStyle = true
+			}
+			b.WriteByte('<')
+			b.WriteString(name)
+			for _, a := range t.Attr {
+				if safeAttr(a.Key, a.Val) {
+					b.WriteByte(' ')
+					b.WriteString(strings.ToLower(a.Key))
+					b.WriteString(`="`)
+					b.WriteString(html.EscapeString(a.Val))
+					b.WriteByte('"')
+				}
+			}
+			if tt == html.SelfClosingTagToken {
+				b.WriteByte('/')
+			}
+			b.WriteByte('>')
+
+		case html.EndTagToken:
+			if skipContentTags[name] && !tags[name] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 || !tags[name] {
+				continue
+			}
+			if name == "style" {
+				inStyle = false
+			}
+			b.WriteString("</")
+			b.WriteString(name)
+			b.WriteByte('>')
+
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			if inStyle {
+				b.WriteString(t.Data)
+			} else {
+				b.WriteString(html.EscapeString(t.Data))
+			}
+
+		case html.CommentToken, html.DoctypeToken:
+			// Doctype is harmless; comments are dropped.
+			if tt == html.DoctypeToken && skipDepth == 0 {
+				b.WriteString("<!DOCTYPE ")
+				b.WriteString(t.Data)
+				b.WriteByte('>')
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      string
+		keep    []string
+		dropped []string
+	}{
+		{
+			"script tag and body removed",
+			`<div>ok</div><script>fetch("http://evil")</script>`,
+			[]string{"<div>ok</div>"},
+			[]string{"script", "evil"},
+		},
+		{
+			"event handler attribute stripped",
+			`<img src="/media/a.png" onerror="alert(1)">`,
+			[]string{`<img src="/media/a.png">`},
+			[]string{"onerror", "alert"},
+		},
+		{
+			"javascript url neutered",
+			`<a href="javascript:alert(1)">x</a>`,
+			[]string{"<a>x</a>"},
+			[]string{"javascript:"},
+		},
+		{
+			"iframe dropped with contents",
+			`<p>hi</p><iframe src="https://evil"></iframe>`,
+			[]string{"<p>hi</p>"},
+			[]string{"iframe", "evil"},
+		},
+		{
+			"benign styled markup survives",
+			`<div style="color:red"><strong>Sale!</strong><style>p{margin:0}</style></div>`,
+			[]string{`style="color:red"`, "<strong>Sale!</strong>", "<style>p{margin:0}</style>"},
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := sanitizeHTML(c.in)
+			for _, want := range c.keep {
+				if !strings.Contains(got, want) {
+					t.Fatalf("expected output to contain %q, got %q", want, got)
+				}
+			}
+			for _, bad := range c.dropped {
+				if strings.Contains(got, bad) {
+					t.Fatalf("expected %q to be removed, got %q", bad, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+// screenshot.go — kiosk screen captures for remote debugging.
+//
+// The kiosk pushes captures (JPEG, PNG, or WebP) to POST
+// /api/kiosk/screenshot; the latest one is kept in memory (never on disk —
+// it's throwaway diagnostic data) and served back to admins with the format
+// it arrived in. Admins can also demand a fresh capture: the request rides
+// the same command channel the kiosk already long-polls for next/prev, and
+// the handler waits briefly for the new image to land before answering.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	defaultScreenshotMaxBytes = 10 << 20 // 10 MB — a 4K JPEG can approach this
+	// Captures wider than this are downscaled before storing; a dashboard
+	// preview never needs native 4K, and the kiosk box is short on RAM.
+	defaultScreenshotMaxWidth = 1280
+	// How long a pull request waits for the kiosk to deliver a fresh capture
+	// before falling back to the stale one.
+	screenshotWait = 5 * time.Second
+)
+
+// screenshotFormats maps accepted content types to the format name
+// image.Decode reports, so a claimed type can be checked against the bytes.
+var screenshotFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// screenshotMaxBytes returns the configured cap (SCREENSHOT_MAX_BYTES), or
+// the default. Mirrors mediaMaxBytes.
+func screenshotMaxBytes() int64 {
+	v := strings.TrimSpace(os.Getenv("SCREENSHOT_MAX_BYTES"))
+	if v == "" {
+		return defaultScreenshotMaxBytes
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("Screenshot: invalid SCREENSHOT_MAX_BYTES %q — using %d", v, int64(defaultScreenshotMaxBytes))
+		return defaultScreenshotMaxBytes
+	}
+	return n
+}
+
+// screenshotMaxWidth returns the widest capture kept as-is
+// (SCREENSHOT_MAX_WIDTH), or the default.
+func screenshotMaxWidth() int {
+	v := strings.TrimSpace(os.Getenv("SCREENSHOT_MAX_WIDTH"))
+	if v == "" {
+		return defaultScreenshotMaxWidth
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("Screenshot: invalid SCREENSHOT_MAX_WIDTH %q — using %d", v, defaultScreenshotMaxWidth)
+		return defaultScreenshotMaxWidth
+	}
+	return n
+}
+
+var (
+	screenshotMu   sync.RWMutex
+	screenshot     []byte
+	screenshotType string
+	screenshotAt   time.Time
+	// WebP re-encode of the same capture, produced in the background when
+	// ffmpeg is available. nil until the conversion lands (or if it failed).
+	screenshotWebP []byte
+)
+
+// handleKioskScreenshot validates and stores the kiosk's pushed capture.
+func handleKioskScreenshot(w http.ResponseWriter, r *http.Request) {
+	ct := r.Header.Get("Content-Type")
+	wantFormat, ok := screenshotFormats[ct]
+	if !ok {
+		http.Error(w, `{"error":"expected image/jpeg, image/png or image/webp"}`, http.StatusUnsupportedMediaType)
+		return
+	}
+	max := screenshotMaxBytes()
+	data, err := io.ReadAll(io.LimitReader(r.Body, max+1))
+	if err != nil {
+		http.Error(w, `{"error":"read failed"}`, http.StatusBadRequest)
+		return
+	}
+	if int64(len(data)) > max {
+		http.Error(w, `{"error":"screenshot too large"}`, http.StatusRequestEntityTooLarge)
+		return
+	}
+	// The bytes must actually decode as the claimed format — a truncated or
+	// mislabelled upload is rejected rather than stored and served broken.
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil || format != wantFormat {
+		http.Error(w, `{"error":"body does not decode as the claimed image format"}`, http.StatusBadRequest)
+		return
+	}
+	// Oversized captures are shrunk to the configured width (aspect ratio
+	// preserved) and re-encoded as JPEG; smaller ones are kept byte-for-byte.
+	if maxW := screenshotMaxWidth(); img.Bounds().Dx() > maxW {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, scaleToWidth(img, maxW), &jpeg.Options{Quality: 80}); err != nil {
+			http.Error(w, `{"error":"downscale failed"}`, http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Screenshot: downscaled %dpx capture to %dpx (%d → %d bytes)",
+			img.Bounds().Dx(), maxW, len(data), buf.Len())
+		data, ct = buf.Bytes(), "image/jpeg"
+	}
+	touchKioskContact()
+	at := time.Now()
+	screenshotMu.Lock()
+	screenshot = data
+	screenshotType = ct
+	screenshotAt = at
+	screenshotWebP = nil
+	screenshotMu.Unlock()
+	// The admin dashboard refetches this one image constantly, so a WebP
+	// variant pays for itself. Converted off the request path; clients that
+	// accept WebP get it once it lands, the original until then.
+	if ct != "image/webp" {
+		go convertScreenshotWebP(data, at)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
+
+// convertScreenshotWebP re-encodes a capture as WebP with ffmpeg (the stdlib
+// decodes WebP but cannot encode it) and stores the result — unless a newer
+// capture has arrived in the meantime.
+func convertScreenshotWebP(data []byte, at time.Time) {
+	webp, err := encodeWebP(data)
+	if err != nil {
+		log.Printf("Screenshot: WebP conversion unavailable: %v", err)
+		return
+	}
+	screenshotMu.Lock()
+	if screenshotAt.Equal(at) {
+		screenshotWebP = webp
+	}
+	screenshotMu.Unlock()
+}
+
+// encodeWebP converts an encoded image to WebP by piping it through ffmpeg,
+// mirroring videoThumbnail's reliance on it being installed. Everything stays
+// in memory — no temp files for throwaway diagnostic data.
+func encodeWebP(data []byte) ([]byte, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not installed")
+	}
+	cmd := exec.Command(ffmpeg, "-y", "-i", "pipe:0", "-frames:v", "1", "-f", "webp", "pipe:1")
+	cmd.Stdin = bytes.NewReader(data)
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %v: %s", err, errOut.Bytes())
+	}
+	return out.Bytes(), nil
+}
+
+// acceptsWebP reports whether the client's Accept header lists image/webp.
+func acceptsWebP(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "image/webp")
+}
+
+// writeScreenshot sends the stored capture with a staleness marker so the
+// dashboard can caption the image honestly. Clients that accept WebP get the
+// smaller converted variant when it's ready.
+func writeScreenshot(w http.ResponseWriter, r *http.Request, stale bool) {
+	screenshotMu.RLock()
+	data, ct, at := screenshot, screenshotType, screenshotAt
+	if acceptsWebP(r) && len(screenshotWebP) > 0 {
+		data, ct = screenshotWebP, "image/webp"
+	}
+	screenshotMu.RUnlock()
+	if len(data) == 0 {
+		http.Error(w, `{"error":"no screenshot received yet"}`, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("X-Screenshot-Stale", strconv.FormatBool(stale))
+	w.Header().Set("X-Screenshot-At", at.UTC().Format(time.RFC3339))
+	_, _ = w.Write(data)
+}
+
+// handleAdminScreenshot returns the most recent capture as-is.
+func handleAdminScreenshot(w http.ResponseWriter, r *http.Request) {
+	writeScreenshot(w, r, false)
+}
+
+// handleAdminScreenshotImage serves the capture with Last-Modified so the
+// dashboard can point an <img src> at it and lean on browser caching —
+// unchanged captures come back 304 instead of re-downloading.
+func handleAdminScreenshotImage(w http.ResponseWriter, r *http.Request) {
+	screenshotMu.RLock()
+	data, ct, at := screenshot, screenshotType, screenshotAt
+	screenshotMu.RUnlock()
+	if len(data) == 0 {
+		http.Error(w, `{"error":"no screenshot received yet"}`, http.StatusNotFound)
+		return
+	}
+	// Set the type explicitly: the capture isn't always JPEG despite the
+	// route name, and ServeContent would otherwise trust the extension.
+	w.Header().Set("Content-Type", ct)
+	http.ServeContent(w, r, "", at, bytes.NewReader(data))
+}
+
+// handleAdminScreenshotInfo returns capture metadata (when, what format, what
+// was on screen) without the image bytes, for dashboards that caption the
+// <img> separately.
+func handleAdminScreenshotInfo(w http.ResponseWriter, r *http.Request) {
+	screenshotMu.RLock()
+	size, ct, at := len(screenshot), screenshotType, screenshotAt
+	screenshotMu.RUnlock()
+	heartbeatMu.RLock()
+	currentAd := lastHeartbeat.CurrentAdID
+	heartbeatMu.RUnlock()
+
+	out := map[string]any{
+		"available":   size > 0,
+		"currentAdId": currentAd,
+	}
+	if size > 0 {
+		out["contentType"] = ct
+		out["bytes"] = size
+		out["takenAt"] = at.UTC().Format(time.RFC3339)
+		out["ageSeconds"] = int(time.Since(at).Seconds())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// handleAdminRequestScreenshot asks the kiosk for a fresh capture and waits
+// (bounded) for it to arrive, so the admin isn't staring at a stale image.
+// On timeout the last known capture is returned flagged stale:true.
+func handleAdminRequestScreenshot(w http.ResponseWriter, r *http.Request) {
+	asked := time.Now()
+	select {
+	case navCmdCh <- "screenshot":
+	default:
+		log.Printf("Screenshot: request dropped — command queue full (kiosk not polling?)")
+		writeScreenshot(w, r, true)
+		return
+	}
+
+	deadline := time.After(screenshotWait)
+	tick := time.NewTicker(150 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			screenshotMu.RLock()
+			fresh := screenshotAt.After(asked)
+			screenshotMu.RUnlock()
+			if fresh {
+				writeScreenshot(w, r, false)
+				return
+			}
+		case <-deadline:
+			log.Printf("Screenshot: kiosk did not deliver within %s — serving last known", screenshotWait)
+			writeScreenshot(w, r, true)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func encodedImage(t *testing.T, format string) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(0, 0, color.Black)
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "png":
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestKioskScreenshotValidation(t *testing.T) {
+	cases := []struct {
+		name     string
+		ct       string
+		body     []byte
+		wantCode int
+	}{
+		{"valid jpeg", "image/jpeg", nil, 200}, // body filled below
+		{"valid png", "image/png", nil, 200},
+		{"mislabelled png as jpeg", "image/jpeg", nil, 400},
+		{"garbage bytes", "image/png", []byte("not an image"), 400},
+		{"unsupported type", "image/gif", []byte("whatever"), 415},
+	}
+	cases[0].body = encodedImage(t, "jpeg")
+	cases[1].body = encodedImage(t, "png")
+	cases[2].body = encodedImage(t, "png")
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/kiosk/screenshot", bytes.NewReader(c.body))
+			req.Header.Set("Content-Type", c.ct)
+			rr := httptest.NewRecorder()
+			handleKioskScreenshot(rr, req)
+			if rr.Code != c.wantCode {
+				t.Fatalf("got %d, want %d", rr.Code, c.wantCode)
+			}
+		})
+	}
+
+	// The last accepted capture is served back with its own content type.
+	rr := httptest.NewRecorder()
+	handleAdminScreenshot(rr, httptest.NewRequest("GET", "/api/admin/screenshot", nil))
+	if rr.Code != 200 || rr.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("serve-back: code=%d ct=%q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+}
+
+func TestScreenshotImageRoute(t *testing.T) {
+	jpg := encodedImage(t, "jpeg")
+	screenshotMu.Lock()
+	screenshot = jpg
+	screenshotType = "image/jpeg"
+	screenshotAt = time.Now().Truncate(time.Second)
+	lastMod := screenshotAt
+	screenshotMu.Unlock()
+
+	rr := httptest.NewRecorder()
+	handleAdminScreenshotImage(rr, httptest.NewRequest("GET", "/api/admin/screenshot.jpg", nil))
+	if rr.Code != 200 || rr.Header().Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("code=%d ct=%q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if rr.Header().Get("Last-Modified") == "" {
+		t.Fatal("missing Last-Modified")
+	}
+	if !bytes.Equal(rr.Body.Bytes(), jpg) {
+		t.Fatal("body is not the raw capture bytes")
+	}
+
+	// A conditional refetch of the unchanged capture is a 304, not a re-send.
+	req := httptest.NewRequest("GET", "/api/admin/screenshot.jpg", nil)
+	req.Header.Set("If-Modified-Since", lastMod.UTC().Format(http.TimeFormat))
+	rr = httptest.NewRecorder()
+	handleAdminScreenshotImage(rr, req)
+	if rr.Code != 304 {
+		t.Fatalf("conditional refetch: got %d, want 304", rr.Code)
+	}
+}
+
+func TestScreenshotWebPNegotiation(t *testing.T) {
+	screenshotMu.Lock()
+	screenshot = encodedImage(t, "jpeg")
+	screenshotType = "image/jpeg"
+	screenshotWebP = nil
+	screenshotMu.Unlock()
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/admin/screenshot", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rr := httptest.NewRecorder()
+		handleAdminScreenshot(rr, req)
+		return rr
+	}
+
+	// No converted variant yet — everyone gets the original.
+	if ct := get("image/webp,*/*").Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Fatalf("before conversion: ct=%q, want image/jpeg", ct)
+	}
+
+	screenshotMu.Lock()
+	screenshotWebP = []byte("fake-webp-bytes")
+	screenshotMu.Unlock()
+
+	if ct := get("image/webp,*/*").Header().Get("Content-Type"); ct != "image/webp" {
+		t.Fatalf("webp client: ct=%q, want image/webp", ct)
+	}
+	rr := get("image/jpeg")
+	if ct := rr.Header().Get("Content-Type"); ct != "image/jpeg" {
+		t.Fatalf("non-webp client: ct=%q, want image/jpeg", ct)
+	}
+	if rr.Header().Get("Vary") != "Accept" {
+		t.Fatalf("missing Vary: Accept on negotiated response")
+	}
+}
+
+func TestKioskScreenshotDownscale(t *testing.T) {
+	t.Setenv("SCREENSHOT_MAX_WIDTH", "100")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 300))); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/api/kiosk/screenshot", &buf)
+	req.Header.Set("Content-Type", "image/png")
+	rr := httptest.NewRecorder()
+	handleKioskScreenshot(rr, req)
+	if rr.Code != 200 {
+		t.Fatalf("push: got %d, want 200", rr.Code)
+	}
+
+	screenshotMu.RLock()
+	data, ct := screenshot, screenshotType
+	screenshotMu.RUnlock()
+	if ct != "image/jpeg" {
+		t.Fatalf("stored type = %q, want image/jpeg after downscale", ct)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 100 || h != 75 {
+		t.Fatalf("stored size = %dx%d, want 100x75", w, h)
+	}
+
+	// A capture already under the limit is stored byte-for-byte.
+	small := encodedImage(t, "png")
+	req = httptest.NewRequest("POST", "/api/kiosk/screenshot", bytes.NewReader(small))
+	req.Header.Set("Content-Type", "image/png")
+	rr = httptest.NewRecorder()
+	handleKioskScreenshot(rr, req)
+	if rr.Code != 200 {
+		t.Fatalf("small push: got %d, want 200", rr.Code)
+	}
+	screenshotMu.RLock()
+	data, ct = screenshot, screenshotType
+	screenshotMu.RUnlock()
+	if ct != "image/png" || !bytes.Equal(data, small) {
+		t.Fatalf("small capture was modified (ct=%q, %d bytes)", ct, len(data))
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Single-instance guard backed by an flock-ed file under the exe dir, so a
+// second launcher exits cleanly instead of panicking on the :6969 bind.
+
+var instanceLockFile *os.File
+
+// acquireInstanceLock takes an exclusive non-blocking lock on
+// exeDir/launcher.lock. It fails when another launcher process holds it.
+func acquireInstanceLock(exeDir string) error {
+	path := filepath.Join(exeDir, "launcher.lock")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return fmt.Errorf("%s is locked by another process", path)
+	}
+	instanceLockFile = f
+	return nil
+}
+
+// releaseInstanceLock drops the lock so a replacement process (the update
+// re-exec) can acquire it before this one finishes exiting.
+func releaseInstanceLock() {
+	if instanceLockFile != nil {
+		_ = syscall.Flock(int(instanceLockFile.Fd()), syscall.LOCK_UN)
+		instanceLockFile.Close()
+		instanceLockFile = nil
+	}
+}
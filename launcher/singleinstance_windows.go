@@ -0,0 +1,49 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Single-instance guard backed by a named mutex, so a second launcher exits
+// cleanly instead of panicking on the :6969 bind.
+
+const instanceMutexName = "Global\\shell-ads-launcher"
+
+var (
+	procCreateMutexW = kernel32DLL.NewProc("CreateMutexW")
+	procReleaseMutex = kernel32DLL.NewProc("ReleaseMutex")
+	instanceMutexHnd uintptr
+)
+
+// acquireInstanceLock creates the named mutex. It fails when another launcher
+// process already holds it. exeDir is unused on Windows.
+func acquireInstanceLock(exeDir string) error {
+	name, err := syscall.UTF16PtrFromString(instanceMutexName)
+	if err != nil {
+		return err
+	}
+	h, _, callErr := procCreateMutexW.Call(0, 1, uintptr(unsafe.Pointer(name)))
+	if h == 0 {
+		return fmt.Errorf("create mutex: %v", callErr)
+	}
+	if callErr == syscall.ERROR_ALREADY_EXISTS {
+		syscall.CloseHandle(syscall.Handle(h))
+		return fmt.Errorf("mutex %q already held", instanceMutexName)
+	}
+	instanceMutexHnd = h
+	return nil
+}
+
+// releaseInstanceLock frees the mutex so a replacement process (the update
+// re-exec) can acquire it before this one finishes exiting.
+func releaseInstanceLock() {
+	if instanceMutexHnd != 0 {
+		procReleaseMutex.Call(instanceMutexHnd)
+		syscall.CloseHandle(syscall.Handle(instanceMutexHnd))
+		instanceMutexHnd = 0
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSpaHandlerFallback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("<html>app</html>")},
+		"assets/app.js": {Data: []byte("console.log(1)")},
+		"favicon.ico":   {Data: []byte{0, 0, 1, 0}},
+	}
+	h := spaHandler(fsys)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	if rr := get("/assets/app.js"); rr.Code != 200 {
+		t.Fatalf("existing asset: got %d, want 200", rr.Code)
+	}
+	if rr := get("/favicon.ico"); rr.Code != 200 {
+		t.Fatalf("embedded favicon: got %d, want 200", rr.Code)
+	}
+	if rr := get("/admin/queue"); rr.Code != 200 || !strings.Contains(rr.Body.String(), "app") {
+		t.Fatalf("route path should fall back to index.html, got %d %q", rr.Code, rr.Body.String())
+	}
+	if rr := get("/assets/missing.js"); rr.Code != 404 {
+		t.Fatalf("missing asset: got %d, want 404", rr.Code)
+	}
+
+	// Without an embedded favicon the handler answers 204 rather than HTML.
+	delete(fsys, "favicon.ico")
+	if rr := get("/favicon.ico"); rr.Code != 204 {
+		t.Fatalf("absent favicon: got %d, want 204", rr.Code)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+// thumbnails.go — small JPEG previews for the admin dashboard.
+//
+// Reviewing ads by name alone is slow, so each image/video ad gets a bounded
+// thumbnail served at /api/admin/thumbnail/{id}. Thumbnails are generated on
+// first request and cached under <mediaDir>/thumbs/ so repeat loads are just
+// a file serve. Images are decoded and scaled with the stdlib; video frames
+// need ffmpeg on PATH, and anything we cannot decode falls back to a
+// generated placeholder.
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+// Thumbnails fit inside this box, preserving aspect ratio.
+const thumbMaxW, thumbMaxH = 320, 180
+
+func thumbsDir() string {
+	return filepath.Join(mediaDir, "thumbs")
+}
+
+// safeFileName mirrors the ID sanitisation used for media downloads.
+func safeFileName(id string) string {
+	var b strings.Builder
+	for _, r := range id {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// handleAdminThumbnail serves the cached thumbnail for an ad, generating it
+// on the first request. Undecodable media gets a placeholder rather than an
+// error so the dashboard grid never has holes.
+func handleAdminThumbnail(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	ad, found := dbGetAd(id)
+	if !found {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	path := filepath.Join(thumbsDir(), safeFileName(ad.ID)+".jpg")
+	if _, err := os.Stat(path); err != nil {
+		if genErr := generateThumbnail(ad, path); genErr != nil {
+			log.Printf("Thumbnail: %q: %v — serving placeholder", ad.ID, genErr)
+			path = placeholderThumb()
+		}
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	http.ServeFile(w, r, path)
+}
+
+// generateThumbnail writes a bounded JPEG for the ad's media to dst.
+func generateThumbnail(ad kioskAd, dst string) error {
+	if err := os.MkdirAll(thumbsDir(), 0o755); err != nil {
+		return err
+	}
+	switch ad.Type {
+	case "image":
+		return imageThumbnail(ad, dst)
+	case "video":
+		return videoThumbnail(ad, dst)
+	default:
+		return fmt.Errorf("no thumbnail for type %q", ad.Type)
+	}
+}
+
+func imageThumbnail(ad kioskAd, dst string) error {
+	var rc io.ReadCloser
+	if strings.HasPrefix(ad.Src, "/media/") {
+		f, err := os.Open(filepath.Join(mediaDir, filepath.Base(ad.Src)))
+		if err != nil {
+			return err
+		}
+		rc = f
+	} else {
+		resp, err := mediaClient.Get(ad.Src)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("media server returned %s", resp.Status)
+		}
+		rc = resp.Body
+	}
+	defer rc.Close()
+
+	img, _, err := image.Decode(io.LimitReader(rc, maxPreviewBytes))
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return writeJPEG(scaleToFit(img), dst)
+}
+
+// videoThumbnail extracts the first frame with ffmpeg when it is installed.
+func videoThumbnail(ad kioskAd, dst string) error {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("ffmpeg not installed")
+	}
+	src := ad.Src
+	if strings.HasPrefix(src, "/media/") {
+		src = filepath.Join(mediaDir, filepath.Base(src))
+	}
+	tmp := dst + ".tmp.jpg"
+	cmd := exec.Command(ffmpeg, "-y", "-i", src, "-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", thumbMaxW, thumbMaxH), tmp)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("ffmpeg: %v: %s", err, out)
+	}
+	return os.Rename(tmp, dst)
+}
+
+// scaleToFit shrinks img to fit the thumbnail box (nearest-neighbour — plenty
+// for a 320px preview, and keeps us on the stdlib).
+func scaleToFit(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= thumbMaxW && h <= thumbMaxH {
+		return img
+	}
+	scale := float64(thumbMaxW) / float64(w)
+	if s := float64(thumbMaxH) / float64(h); s < scale {
+		scale = s
+	}
+	outW, outH := int(float64(w)*scale), int(float64(h)*scale)
+	if outW < 1 {
+		outW = 1
+	}
+	if outH < 1 {
+		outH = 1
+	}
+	return resizeNearest(img, outW, outH)
+}
+
+// scaleToWidth shrinks img to the given width, preserving aspect ratio.
+// Images already at or under the target are returned unchanged.
+func scaleToWidth(img image.Image, maxW int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxW {
+		return img
+	}
+	outH := h * maxW / w
+	if outH < 1 {
+		outH = 1
+	}
+	return resizeNearest(img, maxW, outH)
+}
+
+func resizeNearest(img image.Image, outW, outH int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	out := image.NewRGBA(image.Rect(0, 0, outW, outH))
+	for y := 0; y < outH; y++ {
+		srcY := b.Min.Y + y*h/outH
+		for x := 0; x < outW; x++ {
+			out.Set(x, y, img.At(b.Min.X+x*w/outW, srcY))
+		}
+	}
+	return out
+}
+
+func writeJPEG(img image.Image, dst string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "thumb-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	err = jpeg.Encode(tmp, img, &jpeg.Options{Quality: 80})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, dst)
+}
+
+// placeholderThumb returns (generating once) a flat grey thumbnail used when
+// media cannot be decoded — e.g. video without ffmpeg installed.
+func placeholderThumb() string {
+	path := filepath.Join(thumbsDir(), "placeholder.jpg")
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	_ = os.MkdirAll(thumbsDir(), 0o755)
+	img := image.NewRGBA(image.Rect(0, 0, thumbMaxW, thumbMaxH))
+	grey := color.RGBA{R: 0x3a, G: 0x3a, B: 0x3a, A: 0xff}
+	for y := 0; y < thumbMaxH; y++ {
+		for x := 0; x < thumbMaxW; x++ {
+			img.Set(x, y, grey)
+		}
+	}
+	if err := writeJPEG(img, path); err != nil {
+		log.Printf("Thumbnail: placeholder: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"image"
+	"image/png"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImageThumbnailScalesAndCaches(t *testing.T) {
+	mediaDir = t.TempDir()
+	resetStore(t, []adRecord{
+		{AdID: "pic", Name: "Pic", AdType: "image", Src: "/media/pic.png", Status: adStatusLive},
+	})
+
+	// A 1000×1000 source must come back bounded to the thumbnail box.
+	src := image.NewRGBA(image.Rect(0, 0, 1000, 1000))
+	f, err := os.Create(filepath.Join(mediaDir, "pic.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, src); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	req := httptest.NewRequest("GET", "/api/admin/thumbnail/pic", nil)
+	req.SetPathValue("id", "pic")
+	rr := httptest.NewRecorder()
+	handleAdminThumbnail(rr, req)
+	if rr.Code != 200 {
+		t.Fatalf("got %d, want 200", rr.Code)
+	}
+
+	thumb, err := os.Open(filepath.Join(thumbsDir(), "pic.jpg"))
+	if err != nil {
+		t.Fatalf("thumbnail not cached: %v", err)
+	}
+	defer thumb.Close()
+	cfg, _, err := image.DecodeConfig(thumb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width > thumbMaxW || cfg.Height > thumbMaxH {
+		t.Fatalf("thumbnail %dx%d exceeds bounds %dx%d", cfg.Width, cfg.Height, thumbMaxW, thumbMaxH)
+	}
+}
+
+func TestThumbnailPlaceholderForUndecodable(t *testing.T) {
+	mediaDir = t.TempDir()
+	resetStore(t, []adRecord{
+		{AdID: "clip", Name: "Clip", AdType: "video", Src: "/media/clip.mp4", Status: adStatusLive},
+	})
+	// No ffmpeg in the test environment (and no file either) — the handler
+	// must still answer 200 with the placeholder.
+	t.Setenv("PATH", t.TempDir())
+
+	req := httptest.NewRequest("GET", "/api/admin/thumbnail/clip", nil)
+	req.SetPathValue("id", "clip")
+	rr := httptest.NewRecorder()
+	handleAdminThumbnail(rr, req)
+	if rr.Code != 200 {
+		t.Fatalf("got %d, want 200", rr.Code)
+	}
+	if _, err := os.Stat(filepath.Join(thumbsDir(), "placeholder.jpg")); err != nil {
+		t.Fatalf("placeholder not generated: %v", err)
+	}
+}
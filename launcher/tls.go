@@ -0,0 +1,123 @@
+package main
+
+// tls.go — optional HTTPS for the dashboard server.
+//
+// By default the launcher speaks plain HTTP, which is fine behind a tunnel
+// that terminates TLS but means admin passwords cross the LAN in cleartext
+// otherwise. Two opt-in modes:
+//
+//	TLS_CERT + TLS_KEY       serve with an operator-provided cert/key pair
+//	TLS_SELF_SIGNED=true     generate an in-memory self-signed cert at boot —
+//	                         encryption without a CA, at the cost of a
+//	                         browser warning
+//
+// Unset, behavior is unchanged.
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// dashSrv is the running dashboard server, kept so the updater can drain it
+// before re-exec instead of cutting connections off mid-response.
+var (
+	dashSrvMu sync.Mutex
+	dashSrv   *http.Server
+)
+
+// listenAndServeMaybeTLS starts the dashboard server in whichever TLS mode
+// the environment selects, logging which one is active.
+func listenAndServeMaybeTLS(addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	dashSrvMu.Lock()
+	dashSrv = srv
+	dashSrvMu.Unlock()
+
+	cert, key := os.Getenv("TLS_CERT"), os.Getenv("TLS_KEY")
+	if cert != "" && key != "" {
+		log.Printf("Dash: serving HTTPS with cert %s", cert)
+		return srv.ListenAndServeTLS(cert, key)
+	}
+
+	if os.Getenv("TLS_SELF_SIGNED") == "true" {
+		tlsCert, err := selfSignedCert()
+		if err != nil {
+			log.Printf("Dash: self-signed cert generation failed (%v) — falling back to HTTP", err)
+			return srv.ListenAndServe()
+		}
+		log.Printf("Dash: serving HTTPS with a self-signed certificate")
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{tlsCert}}
+		return srv.ListenAndServeTLS("", "")
+	}
+
+	log.Printf("Dash: serving plain HTTP (set TLS_CERT/TLS_KEY or TLS_SELF_SIGNED=true for HTTPS)")
+	return srv.ListenAndServe()
+}
+
+// shutdownDashServer drains in-flight requests before the launcher exits for
+// a self-update, so the kiosk never sees a truncated playlist or a dropped
+// long-poll. The timeout keeps eternal connections (the SSE stream) from
+// holding the restart hostage; whatever hasn't finished by then is cut.
+func shutdownDashServer(timeout time.Duration) {
+	dashSrvMu.Lock()
+	srv := dashSrv
+	dashSrvMu.Unlock()
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Dash: shutdown drain incomplete after %s: %v", timeout, err)
+	} else {
+		log.Printf("Dash: connections drained")
+	}
+}
+
+// selfSignedCert builds a fresh one-year ECDSA certificate for localhost and
+// the machine's LAN addresses. Nothing touches disk.
+func selfSignedCert() (tls.Certificate, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "shell-ads-launcher"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range addrs {
+			if ipn, ok := a.(*net.IPNet); ok && !ipn.IP.IsLoopback() {
+				tmpl.IPAddresses = append(tmpl.IPAddresses, ipn.IP)
+			}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+	}, nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeBundleFile(t *testing.T, dir, name string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateBundle(t *testing.T) {
+	pe := []byte("MZ\x90\x00fake-binary")
+
+	t.Run("complete bundle passes", func(t *testing.T) {
+		dir := t.TempDir()
+		writeBundleFile(t, dir, "kiosk.exe", pe)
+		writeBundleFile(t, dir, "launcher.exe", pe)
+		if err := validateBundle(dir); err != nil {
+			t.Fatalf("expected valid bundle, got %v", err)
+		}
+	})
+
+	t.Run("missing kiosk.exe rejected", func(t *testing.T) {
+		dir := t.TempDir()
+		writeBundleFile(t, dir, "launcher.exe", pe)
+		if err := validateBundle(dir); err == nil {
+			t.Fatal("expected error for missing kiosk.exe")
+		}
+	})
+
+	t.Run("empty binary rejected", func(t *testing.T) {
+		dir := t.TempDir()
+		writeBundleFile(t, dir, "kiosk.exe", nil)
+		writeBundleFile(t, dir, "launcher.exe", pe)
+		if err := validateBundle(dir); err == nil {
+			t.Fatal("expected error for empty kiosk.exe")
+		}
+	})
+
+	t.Run("wrong magic rejected", func(t *testing.T) {
+		dir := t.TempDir()
+		writeBundleFile(t, dir, "kiosk.exe", []byte("\x7fELF not windows"))
+		writeBundleFile(t, dir, "launcher.exe", pe)
+		if err := validateBundle(dir); err == nil {
+			t.Fatal("expected error for non-PE kiosk.exe")
+		}
+	})
+}
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	cases := []struct {
+		in         string
+		ok         bool
+		start, end int
+	}{
+		{"02:00-05:00", true, 120, 300},
+		{"22:00 - 03:00", true, 1320, 180},
+		{"2am-5am", false, 0, 0},
+		{"02:00", false, 0, 0},
+	}
+	for _, c := range cases {
+		start, end, ok := parseMaintenanceWindow(c.in)
+		if ok != c.ok {
+			t.Fatalf("parseMaintenanceWindow(%q) ok = %v, want %v", c.in, ok, c.ok)
+		}
+		if ok && (start != c.start || end != c.end) {
+			t.Fatalf("parseMaintenanceWindow(%q) = %d-%d, want %d-%d", c.in, start, end, c.start, c.end)
+		}
+	}
+}
+
+func TestVerifyBundleSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedKey := updatePublicKeyHex
+	updatePublicKeyHex = hex.EncodeToString(pub)
+	defer func() { updatePublicKeyHex = savedKey }()
+
+	bundle := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := os.WriteFile(bundle, []byte("bundle-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sig := ed25519.Sign(priv, []byte("bundle-bytes"))
+
+	if err := verifyBundleSignature(bundle, sig); err != nil {
+		t.Fatalf("valid raw signature rejected: %v", err)
+	}
+	if err := verifyBundleSignature(bundle, []byte(hex.EncodeToString(sig))); err != nil {
+		t.Fatalf("valid hex signature rejected: %v", err)
+	}
+	bad := ed25519.Sign(priv, []byte("other-bytes"))
+	if err := verifyBundleSignature(bundle, bad); err == nil {
+		t.Fatal("signature over different bytes should be rejected")
+	}
+	if err := verifyBundleSignature(bundle, []byte("short")); err == nil {
+		t.Fatal("truncated signature should be rejected")
+	}
+
+	updatePublicKeyHex = ""
+	if err := verifyBundleSignature(bundle, sig); err == nil {
+		t.Fatal("missing embedded key should be rejected")
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "kiosk.exe")
+	writeBundleFile(t, dir, "kiosk.exe", []byte("MZ-original"))
+
+	// A failed staging copy (missing source) must leave the original intact.
+	if err := replaceExecutable(filepath.Join(dir, "no-such-file"), dst); err == nil {
+		t.Fatal("expected error for missing source")
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil || string(got) != "MZ-original" {
+		t.Fatalf("original binary damaged after failed replace: %q, %v", got, err)
+	}
+
+	// A successful replace installs the new binary and keeps a rollback copy.
+	writeBundleFile(t, dir, "incoming.exe", []byte("MZ-updated"))
+	if err := replaceExecutable(filepath.Join(dir, "incoming.exe"), dst); err != nil {
+		t.Fatalf("replace failed: %v", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "MZ-updated" {
+		t.Fatalf("new binary not installed: %q", got)
+	}
+	if got, _ := os.ReadFile(dst + ".old"); string(got) != "MZ-original" {
+		t.Fatalf("rollback copy missing or wrong: %q", got)
+	}
+	if _, err := os.Stat(dst + ".new"); !os.IsNotExist(err) {
+		t.Fatal("staging file should not remain after success")
+	}
+}
+
+func TestCompareBuilds(t *testing.T) {
+	cases := []struct {
+		name    string
+		current int
+		tag     string
+		latest  int
+		newer   bool
+	}{
+		{"newer build", 41, "build-42", 42, true},
+		{"equal build", 42, "build-42", 42, false},
+		{"older build", 43, "build-42", 42, false},
+		{"dev build sees any release", 0, "build-1", 1, true},
+		{"empty tag", 5, "", 0, false},
+		{"missing prefix", 5, "v1.2.3", 0, false},
+		{"non-numeric suffix", 5, "build-abc", 0, false},
+		{"trailing junk", 5, "build-42-rc1", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			latest, newer := compareBuilds(c.current, c.tag)
+			if latest != c.latest || newer != c.newer {
+				t.Fatalf("compareBuilds(%d, %q) = (%d, %v), want (%d, %v)",
+					c.current, c.tag, latest, newer, c.latest, c.newer)
+			}
+		})
+	}
+}
+
+func TestCheckDirWritable(t *testing.T) {
+	if err := checkDirWritable(t.TempDir()); err != nil {
+		t.Fatalf("writable dir reported unwritable: %v", err)
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+	ro := filepath.Join(t.TempDir(), "ro")
+	if err := os.Mkdir(ro, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkDirWritable(ro); err == nil {
+		t.Fatal("read-only dir reported writable")
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+// uploads.go — direct multipart file upload for ad submissions.
+//
+// The JSON submit path takes a URL and downloads it, which forces users
+// through file.io-style hosts whose one-time links love to die mid-fetch.
+// This path lets the dash POST the file itself: the upload is streamed to
+// mediaDir under a content-hash name, and the ad's src points straight at
+// the local /media/ path — no remote fetch at all.
+//
+// Multipart layout:
+//
+//	field "ad"    JSON-encoded dashAd ("image" or "video"; url is ignored)
+//	file  "file"  the media file itself
+//
+// HTML-type ads in the same multipart envelope are handled by htmlads.go.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultUploadMaxBytes caps a single uploaded file; UPLOAD_MAX_BYTES
+// overrides it. Distinct from MEDIA_MAX_BYTES, which bounds the whole cache.
+const defaultUploadMaxBytes = 512 << 20
+
+func uploadMaxBytes() int64 {
+	v := strings.TrimSpace(os.Getenv("UPLOAD_MAX_BYTES"))
+	if v == "" {
+		return defaultUploadMaxBytes
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("Upload: invalid UPLOAD_MAX_BYTES %q — using default", v)
+		return defaultUploadMaxBytes
+	}
+	return n
+}
+
+// uploadExts maps allowed upload extensions to the ad type they belong to,
+// mirroring the set downloadToMedia will cache.
+var uploadExts = map[string]string{
+	".png": "image", ".jpg": "image", ".jpeg": "image", ".webp": "image", ".gif": "image",
+	".mp4": "video", ".webm": "video",
+}
+
+// hashName builds the content-addressed file name for a media file.
+// 16 hex chars of SHA-256 is plenty of collision margin for one kiosk's
+// media set while keeping /media/ URLs readable.
+func hashName(sum []byte, ext string) string {
+	return hex.EncodeToString(sum)[:16] + ext
+}
+
+// contentHashName hashes a file already on disk and returns its
+// content-addressed name.
+func contentHashName(path, ext string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hashName(h.Sum(nil), ext), nil
+}
+
+// handleSubmitMultipart is the multipart branch of /api/submit-ads: one ad
+// per request, with its payload carried in the form rather than fetched from
+// a URL. Dispatches on the declared ad type.
+func handleSubmitMultipart(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(64 << 20); err != nil {
+		http.Error(w, `{"error":"bad multipart form"}`, http.StatusBadRequest)
+		return
+	}
+	var d dashAd
+	if err := json.Unmarshal([]byte(r.FormValue("ad")), &d); err != nil {
+		http.Error(w, `{"error":"bad ad JSON in form field"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Same gates the JSON path applies per ad.
+	_, email := splitSubmitter(d.SubmittedBy)
+	if dbIsBanned(email) {
+		http.Error(w, `{"error":"submitter is banned"}`, http.StatusForbidden)
+		return
+	}
+	if quota := dbMaxAdsPerUser(); quota > 0 {
+		if used := dbPipelineCount(email); used >= quota {
+			msg := fmt.Sprintf(`{"error":"you have %d/%d pending ads; remove one first"}`, used, quota)
+			http.Error(w, msg, http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	switch d.Type {
+	case "html":
+		submitHTMLAd(w, r, d)
+	case "image", "video":
+		submitUploadedAd(w, r, d)
+	default:
+		http.Error(w, `{"error":"multipart submissions must be html, image or video"}`, http.StatusBadRequest)
+	}
+}
+
+// submitUploadedAd stores the uploaded file and queues the ad for review
+// with src already pointing at the local copy.
+func submitUploadedAd(w http.ResponseWriter, r *http.Request, d dashAd) {
+	files := r.MultipartForm.File["file"]
+	if len(files) != 1 {
+		http.Error(w, `{"error":"expected exactly one file part"}`, http.StatusBadRequest)
+		return
+	}
+	fh := files[0]
+	name, err := storeUploadedMedia(d.Type, fh)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":"file %q: %s"}`, fh.Filename, err), http.StatusBadRequest)
+		return
+	}
+
+	durMs := d.DurationSec * 1000
+	if durMs <= 0 {
+		durMs = dbDefaultDurationMs()
+	}
+	durationMode := ""
+	if d.DurationMode == "auto" && d.Type == "video" {
+		durationMode = "auto"
+	}
+	if d.Type != "video" {
+		const minMs, maxMs = 1000, 30000
+		if durMs < minMs {
+			durMs = minMs
+		}
+		if durMs > maxMs {
+			durMs = maxMs
+		}
+	}
+	ad := kioskAd{
+		ID:           d.ID,
+		Name:         d.Name,
+		Type:         d.Type,
+		DurationMs:   durMs,
+		DurationMode: durationMode,
+		Src:          "/media/" + name,
+		Transition:   adTransition{Enter: normalizeTransition(d.Enter), Exit: normalizeTransition(d.Exit)},
+		SubmittedBy:  d.SubmittedBy,
+	}
+	if err := dbSaveAd(ad, ""); err != nil {
+		log.Printf("Submit: failed to save uploaded ad %q: %v", d.ID, err)
+		http.Error(w, `{"error":"could not be saved"}`, http.StatusInternalServerError)
+		return
+	}
+	if durationMode == "auto" {
+		maybeAutoDuration(d.ID)
+	}
+
+	log.Printf("Submit: uploaded ad %q (%s, %d bytes) queued for review", d.ID, name, fh.Size)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok":       true,
+		"accepted": 1,
+		"results": []map[string]any{
+			{"id": d.ID, "accepted": true},
+		},
+	})
+}
+
+// storeUploadedMedia streams one uploaded file into mediaDir, hashing as it
+// copies, and returns the content-addressed file name. An identical file
+// already on disk is reused rather than rewritten.
+func storeUploadedMedia(adType string, fh *multipart.FileHeader) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path.Base(fh.Filename)))
+	if uploadExts[ext] != adType {
+		return "", fmt.Errorf("type %s not allowed for %s ads", ext, adType)
+	}
+	maxBytes := uploadMaxBytes()
+	if fh.Size > maxBytes {
+		return "", fmt.Errorf("too large (max %d MB)", maxBytes>>20)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return "", fmt.Errorf("unreadable")
+	}
+	defer f.Close()
+
+	tmp, err := os.CreateTemp(mediaDir, "upload-*")
+	if err != nil {
+		return "", fmt.Errorf("could not store")
+	}
+	tmpName := tmp.Name()
+	h := sha256.New()
+	// The declared size can lie; cap the bytes actually read too.
+	n, err := io.Copy(tmp, io.TeeReader(io.LimitReader(f, maxBytes+1), h))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return "", fmt.Errorf("could not store")
+	}
+	if n > maxBytes {
+		os.Remove(tmpName)
+		return "", fmt.Errorf("too large (max %d MB)", maxBytes>>20)
+	}
+
+	name := hashName(h.Sum(nil), ext)
+	destPath := filepath.Join(mediaDir, name)
+	if _, err := os.Stat(destPath); err == nil {
+		// Same content already cached — reuse it.
+		os.Remove(tmpName)
+		log.Printf("Media: upload %s matches cached %s — reusing", fh.Filename, name)
+		return name, nil
+	}
+	if err := os.Rename(tmpName, destPath); err != nil {
+		os.Remove(tmpName)
+		return "", fmt.Errorf("could not store")
+	}
+	return name, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// multipartUpload builds a multipart /api/submit-ads request for one direct
+// file upload.
+func multipartUpload(t *testing.T, adJSON, fileName string, data []byte) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("ad", adJSON); err != nil {
+		t.Fatal(err)
+	}
+	fw, err := mw.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	mw.Close()
+	req := httptest.NewRequest("POST", "/api/submit-ads", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestSubmitUploadedAd(t *testing.T) {
+	resetStore(t, nil)
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	payload := []byte("fake png bytes")
+	req := multipartUpload(t,
+		`{"id":"up-1","name":"Poster","type":"image","durationSec":8,"submittedBy":"Pat <pat@example.com>"}`,
+		"poster.png", payload)
+	rec := httptest.NewRecorder()
+	handleSubmitAds(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	sum := sha256.Sum256(payload)
+	wantName := hex.EncodeToString(sum[:])[:16] + ".png"
+	ad, found := dbGetAd("up-1")
+	if !found {
+		t.Fatal("ad not saved")
+	}
+	if ad.Src != "/media/"+wantName {
+		t.Fatalf("src = %q, want /media/%s", ad.Src, wantName)
+	}
+	if _, err := os.Stat(filepath.Join(mediaDir, wantName)); err != nil {
+		t.Fatalf("uploaded file not stored: %v", err)
+	}
+
+	// A second upload of identical content reuses the cached file.
+	req = multipartUpload(t,
+		`{"id":"up-2","name":"Same poster","type":"image","submittedBy":"Sam <sam@example.com>"}`,
+		"copy.png", payload)
+	rec = httptest.NewRecorder()
+	handleSubmitAds(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("duplicate upload status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	ad2, _ := dbGetAd("up-2")
+	if ad2.Src != ad.Src {
+		t.Errorf("duplicate content got src %q, want %q", ad2.Src, ad.Src)
+	}
+	entries, _ := os.ReadDir(mediaDir)
+	if len(entries) != 1 {
+		t.Errorf("media dir has %d files, want 1 (deduped)", len(entries))
+	}
+}
+
+func TestSubmitUploadRejectsWrongType(t *testing.T) {
+	resetStore(t, nil)
+	oldMedia := mediaDir
+	mediaDir = t.TempDir()
+	defer func() { mediaDir = oldMedia }()
+
+	for _, c := range []struct{ adType, fileName string }{
+		{"image", "clip.mp4"}, // video file on an image ad
+		{"image", "run.exe"},  // not media at all
+	} {
+		req := multipartUpload(t,
+			`{"id":"bad-1","name":"Nope","type":"`+c.adType+`","submittedBy":"Pat <pat@example.com>"}`,
+			c.fileName, []byte("data"))
+		rec := httptest.NewRecorder()
+		handleSubmitAds(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s/%s: status = %d, want 400", c.adType, c.fileName, rec.Code)
+		}
+	}
+	if _, found := dbGetAd("bad-1"); found {
+		t.Error("rejected ad was saved anyway")
+	}
+	if entries, _ := os.ReadDir(mediaDir); len(entries) != 0 {
+		t.Errorf("media dir not clean after rejections: %v", entries)
+	}
+}
@@ -12,3 +12,13 @@ func PreventWindowsSleep() {
 func AllowWindowsSleep() {
 	// Not needed on non-Windows systems
 }
+
+// SetMonitorPower is a no-op on non-Windows platforms
+func SetMonitorPower(on bool) {
+	// Monitor power control uses a Windows-only broadcast message
+}
+
+// SleepReassertCount is always zero on non-Windows platforms.
+func SleepReassertCount() int64 {
+	return 0
+}
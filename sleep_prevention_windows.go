@@ -4,12 +4,17 @@
 package main
 
 import (
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 var (
 	kernel32                    = syscall.NewLazyDLL("kernel32.dll")
 	procSetThreadExecutionState = kernel32.NewProc("SetThreadExecutionState")
+	user32                      = syscall.NewLazyDLL("user32.dll")
+	procSendMessageW            = user32.NewProc("SendMessageW")
 )
 
 // ExecutionState flags for SetThreadExecutionState
@@ -20,18 +25,77 @@ const (
 	ES_CONTINUOUS       = 0x80000000
 )
 
+var (
+	// sleepPrevented tracks the currently desired state so the watchdog
+	// knows whether to keep re-asserting it.
+	sleepPrevented atomic.Bool
+	// sleepReasserts counts watchdog re-assertions; a climbing number means
+	// another process keeps resetting the execution state.
+	sleepReasserts atomic.Int64
+	watchdogOnce   sync.Once
+)
+
+// SendMessage parameters for monitor power control.
+const (
+	HWND_BROADCAST  = 0xffff
+	WM_SYSCOMMAND   = 0x0112
+	SC_MONITORPOWER = 0xf170
+
+	monitorPowerOff = 2 // lParam values defined by WM_SYSCOMMAND
+)
+
+// SetMonitorPower turns the display panel on or off via the broadcast
+// SC_MONITORPOWER system command. This is the opposite concern from sleep
+// prevention: it actively powers the panel down when the venue is closed.
+// Note that the sleep watchdog's ES_DISPLAY_REQUIRED re-assertion can wake
+// the panel back up, so operators should keep DISPLAY_POWER_HOURS inside
+// AWAKE_HOURS (the panel is only forced off while sleep may happen anyway).
+func SetMonitorPower(on bool) {
+	lParam := uintptr(monitorPowerOff)
+	if on {
+		// -1 is "power on" in the SC_MONITORPOWER contract.
+		lParam = ^uintptr(0)
+	}
+	procSendMessageW.Call(uintptr(HWND_BROADCAST), uintptr(WM_SYSCOMMAND), uintptr(SC_MONITORPOWER), lParam)
+}
+
 // PreventWindowsSleep prevents the Windows system from sleeping by setting
-// the thread execution state to keep the system active.
+// the thread execution state to keep the system active. Some display drivers
+// ignore or reset a one-shot call, so a watchdog re-asserts it every minute
+// for as long as sleep prevention is wanted.
 func PreventWindowsSleep() {
-	// ES_CONTINUOUS | ES_SYSTEM_REQUIRED | ES_DISPLAY_REQUIRED
-	// This keeps both the system and display awake
-	state := uintptr(ES_CONTINUOUS | ES_SYSTEM_REQUIRED | ES_DISPLAY_REQUIRED)
-	procSetThreadExecutionState.Call(state)
+	sleepPrevented.Store(true)
+	setExecutionStateAwake()
+	watchdogOnce.Do(func() { go sleepWatchdog() })
 }
 
 // AllowWindowsSleep allows the Windows system to sleep normally
 func AllowWindowsSleep() {
+	sleepPrevented.Store(false)
 	// ES_CONTINUOUS with no other flags resets to normal sleep behavior
-	state := uintptr(ES_CONTINUOUS)
+	procSetThreadExecutionState.Call(uintptr(ES_CONTINUOUS))
+}
+
+// SleepReassertCount returns how many times the watchdog had to re-assert
+// the execution state after the initial call.
+func SleepReassertCount() int64 {
+	return sleepReasserts.Load()
+}
+
+func setExecutionStateAwake() {
+	// ES_CONTINUOUS | ES_SYSTEM_REQUIRED | ES_DISPLAY_REQUIRED
+	// This keeps both the system and display awake
+	state := uintptr(ES_CONTINUOUS | ES_SYSTEM_REQUIRED | ES_DISPLAY_REQUIRED)
 	procSetThreadExecutionState.Call(state)
 }
+
+// sleepWatchdog re-asserts the awake state once a minute while it is wanted,
+// since SetThreadExecutionState can be reset by other processes.
+func sleepWatchdog() {
+	for range time.Tick(time.Minute) {
+		if sleepPrevented.Load() {
+			setExecutionStateAwake()
+			sleepReasserts.Add(1)
+		}
+	}
+}
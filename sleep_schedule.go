@@ -0,0 +1,139 @@
+package main
+
+// sleep_schedule.go — keeps the display awake only during configured hours.
+//
+// AWAKE_HOURS takes a local-time window as "HH:MM-HH:MM" (e.g. "06:00-22:00").
+// Inside the window the kiosk prevents sleep; outside it the system may sleep
+// normally. Overnight windows such as "18:00-02:00" work too. When the
+// variable is unset or malformed the kiosk stays awake around the clock,
+// matching the previous always-on behaviour.
+//
+// DISPLAY_POWER_HOURS takes the same format and goes one step further:
+// outside its window the panel itself is powered down (SC_MONITORPOWER),
+// saving energy and screen lifetime while the venue is closed. Unset means
+// the panel is never touched.
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// awakeWindow holds the window boundaries as minutes past local midnight.
+type awakeWindow struct {
+	startMin int
+	endMin   int
+}
+
+// parseAwakeWindow parses "HH:MM-HH:MM". A window whose start equals its end
+// is treated as always-on.
+func parseAwakeWindow(s string) (awakeWindow, bool) {
+	parts := strings.SplitN(strings.TrimSpace(s), "-", 2)
+	if len(parts) != 2 {
+		return awakeWindow{}, false
+	}
+	start, ok1 := parseClock(parts[0])
+	end, ok2 := parseClock(parts[1])
+	if !ok1 || !ok2 {
+		return awakeWindow{}, false
+	}
+	return awakeWindow{startMin: start, endMin: end}, true
+}
+
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// contains reports whether t falls inside the window.
+func (w awakeWindow) contains(t time.Time) bool {
+	if w.startMin == w.endMin {
+		return true
+	}
+	m := t.Hour()*60 + t.Minute()
+	if w.startMin < w.endMin {
+		return m >= w.startMin && m < w.endMin
+	}
+	// Overnight window, e.g. 18:00-02:00.
+	return m >= w.startMin || m < w.endMin
+}
+
+// nextBoundary returns the earliest start or end boundary strictly after t.
+func (w awakeWindow) nextBoundary(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	next := time.Time{}
+	for _, min := range []int{w.startMin, w.endMin} {
+		b := midnight.Add(time.Duration(min) * time.Minute)
+		if !b.After(t) {
+			b = b.Add(24 * time.Hour)
+		}
+		if next.IsZero() || b.Before(next) {
+			next = b
+		}
+	}
+	return next
+}
+
+// startSleepScheduler applies the configured awake window, including when the
+// app starts mid-window, and keeps re-applying at each boundary.
+func startSleepScheduler() {
+	spec := strings.TrimSpace(os.Getenv("AWAKE_HOURS"))
+	if spec == "" {
+		PreventWindowsSleep()
+		return
+	}
+	win, ok := parseAwakeWindow(spec)
+	if !ok {
+		println("Invalid AWAKE_HOURS value, staying awake:", spec)
+		PreventWindowsSleep()
+		return
+	}
+
+	apply := func(now time.Time) {
+		if win.contains(now) {
+			PreventWindowsSleep()
+		} else {
+			AllowWindowsSleep()
+		}
+	}
+	apply(time.Now())
+
+	go func() {
+		for {
+			// Sleep slightly past the boundary so the minute comparison in
+			// contains() lands on the right side of it.
+			time.Sleep(time.Until(win.nextBoundary(time.Now())) + time.Second)
+			apply(time.Now())
+		}
+	}()
+}
+
+// startDisplayPowerScheduler powers the panel down outside the configured
+// DISPLAY_POWER_HOURS window and back on at its start, including when the
+// app starts outside the window.
+func startDisplayPowerScheduler() {
+	spec := strings.TrimSpace(os.Getenv("DISPLAY_POWER_HOURS"))
+	if spec == "" {
+		return
+	}
+	win, ok := parseAwakeWindow(spec)
+	if !ok {
+		println("Invalid DISPLAY_POWER_HOURS value, leaving the panel alone:", spec)
+		return
+	}
+
+	apply := func(now time.Time) {
+		SetMonitorPower(win.contains(now))
+	}
+	apply(time.Now())
+
+	go func() {
+		for {
+			time.Sleep(time.Until(win.nextBoundary(time.Now())) + time.Second)
+			apply(time.Now())
+		}
+	}()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAwakeWindow(t *testing.T) {
+	cases := []struct {
+		in         string
+		ok         bool
+		start, end int
+	}{
+		{"06:00-22:00", true, 360, 1320},
+		{" 18:00 - 02:00 ", true, 1080, 120},
+		{"6am-10pm", false, 0, 0},
+		{"06:00", false, 0, 0},
+		{"", false, 0, 0},
+	}
+	for _, c := range cases {
+		w, ok := parseAwakeWindow(c.in)
+		if ok != c.ok {
+			t.Fatalf("parseAwakeWindow(%q) ok = %v, want %v", c.in, ok, c.ok)
+		}
+		if ok && (w.startMin != c.start || w.endMin != c.end) {
+			t.Fatalf("parseAwakeWindow(%q) = %d-%d, want %d-%d", c.in, w.startMin, w.endMin, c.start, c.end)
+		}
+	}
+}
+
+func TestAwakeWindowContains(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2026, 1, 5, hour, min, 0, 0, time.Local)
+	}
+
+	day := awakeWindow{startMin: 360, endMin: 1320} // 06:00-22:00
+	if !day.contains(at(6, 0)) || !day.contains(at(12, 0)) {
+		t.Fatal("daytime window should contain business hours")
+	}
+	if day.contains(at(22, 0)) || day.contains(at(3, 0)) {
+		t.Fatal("daytime window should exclude overnight hours")
+	}
+
+	night := awakeWindow{startMin: 1080, endMin: 120} // 18:00-02:00
+	if !night.contains(at(23, 30)) || !night.contains(at(1, 59)) {
+		t.Fatal("overnight window should span midnight")
+	}
+	if night.contains(at(2, 0)) || night.contains(at(12, 0)) {
+		t.Fatal("overnight window should exclude daytime")
+	}
+
+	if !(awakeWindow{startMin: 0, endMin: 0}).contains(at(4, 0)) {
+		t.Fatal("zero-length window should mean always-on")
+	}
+}
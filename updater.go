@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/selfupdate"
@@ -47,8 +48,40 @@ func currentBuildInt() int {
 	return n
 }
 
-// fetchLatestRelease queries the GitHub releases API and returns the latest release.
+// compareBuilds parses a "build-N" release tag and reports whether it is
+// newer than the current build. Malformed or empty tags parse to build 0,
+// which is never newer. Kept identical to the launcher's copy so both
+// updaters make the same decision for the same tag.
+func compareBuilds(currentBuild int, tag string) (latestBuild int, newer bool) {
+	if after, ok := strings.CutPrefix(tag, "build-"); ok {
+		latestBuild, _ = strconv.Atoi(after)
+	}
+	return latestBuild, latestBuild > currentBuild
+}
+
+// releaseCacheTTL keeps repeated update checks (dev overlay refreshes, many
+// kiosks behind one NAT) from burning the 60 req/hr unauthenticated limit.
+const releaseCacheTTL = 5 * time.Minute
+
+var (
+	releaseCacheMu    sync.Mutex
+	cachedRelease     *ghRelease
+	releaseCacheValid bool
+	releaseCachedAt   time.Time
+)
+
+// fetchLatestRelease queries the GitHub releases API and returns the latest
+// release, reusing a recent result within the cache TTL. A GITHUB_TOKEN env
+// var is sent as a Bearer token when present to raise the rate limit.
 func (a *App) fetchLatestRelease() (*ghRelease, error) {
+	releaseCacheMu.Lock()
+	if releaseCacheValid && time.Since(releaseCachedAt) < releaseCacheTTL {
+		r := cachedRelease
+		releaseCacheMu.Unlock()
+		return r, nil
+	}
+	releaseCacheMu.Unlock()
+
 	url := fmt.Sprintf(
 		"https://api.github.com/repos/%s/%s/releases/latest",
 		githubOwner, githubRepo,
@@ -59,6 +92,9 @@ func (a *App) fetchLatestRelease() (*ghRelease, error) {
 	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "ShellNews-Bernard-updater")
+	if tok := strings.TrimSpace(os.Getenv("GITHUB_TOKEN")); tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -78,6 +114,12 @@ func (a *App) fetchLatestRelease() (*ghRelease, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
 		return nil, err
 	}
+
+	releaseCacheMu.Lock()
+	cachedRelease = &release
+	releaseCacheValid = true
+	releaseCachedAt = time.Now()
+	releaseCacheMu.Unlock()
 	return &release, nil
 }
 
@@ -95,13 +137,10 @@ func (a *App) CheckForUpdate() (*UpdateInfo, error) {
 	}
 
 	// Tag format: "build-42"
-	latest := 0
-	if after, ok := strings.CutPrefix(release.TagName, "build-"); ok {
-		latest, _ = strconv.Atoi(after)
-	}
+	latest, newer := compareBuilds(current, release.TagName)
 
 	return &UpdateInfo{
-		Available:    latest > current,
+		Available:    newer,
 		LatestBuild:  latest,
 		CurrentBuild: current,
 		ReleaseURL:   release.HTMLURL,
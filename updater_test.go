@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCompareBuilds(t *testing.T) {
+	cases := []struct {
+		name    string
+		current int
+		tag     string
+		latest  int
+		newer   bool
+	}{
+		{"newer build", 41, "build-42", 42, true},
+		{"equal build", 42, "build-42", 42, false},
+		{"older build", 43, "build-42", 42, false},
+		{"dev build sees any release", 0, "build-1", 1, true},
+		{"empty tag", 5, "", 0, false},
+		{"missing prefix", 5, "v1.2.3", 0, false},
+		{"non-numeric suffix", 5, "build-abc", 0, false},
+		{"trailing junk", 5, "build-42-rc1", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			latest, newer := compareBuilds(c.current, c.tag)
+			if latest != c.latest || newer != c.newer {
+				t.Fatalf("compareBuilds(%d, %q) = (%d, %v), want (%d, %v)",
+					c.current, c.tag, latest, newer, c.latest, c.newer)
+			}
+		})
+	}
+}